@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/scttfrdmn/gh-label-sync/pkg/actions"
+	"github.com/scttfrdmn/gh-label-sync/pkg/diff"
+	"github.com/scttfrdmn/gh-label-sync/pkg/parser"
+	"github.com/spf13/cobra"
+)
+
+var actionCmd = &cobra.Command{
+	Use:   "action",
+	Short: "Run as a GitHub Action",
+	Long: `Sync labels using GitHub Action inputs.
+
+Inputs are read from INPUT_* environment variables as set by the Actions runner:
+  file              Label definition file (default: .github/labels.yml)
+  repo              Target repository (default: $GITHUB_REPOSITORY)
+  token             Token used for API calls (default: $GH_TOKEN)
+  force             Update existing labels that differ (default: false)
+  delete-unmanaged  Delete labels not in file (default: false)
+  dry-run           Show what would change without applying (default: false)
+  vars              Template variables, one NAME=VALUE per line
+  sort              Order of changes: file, name, or type (default: file)
+  group             Group changes by label prefix (default: false)
+
+Invalid label definitions are reported as workflow error annotations, and the
+created, updated, and deleted counts are set as step outputs.`,
+	Args: cobra.NoArgs,
+	RunE: runAction,
+}
+
+func runAction(cmd *cobra.Command, args []string) error {
+	file := actions.Input("file")
+	if file == "" {
+		file = ".github/labels.yml"
+	}
+
+	repo := actions.Input("repo")
+	if repo == "" {
+		repo = os.Getenv("GITHUB_REPOSITORY")
+	}
+
+	if token := actions.Input("token"); token != "" {
+		os.Setenv("GH_TOKEN", token)
+	}
+
+	force, err := actions.BoolInput("force", false)
+	if err != nil {
+		return err
+	}
+	deleteUnmanaged, err := actions.BoolInput("delete-unmanaged", false)
+	if err != nil {
+		return err
+	}
+	dryRun, err := actions.BoolInput("dry-run", false)
+	if err != nil {
+		return err
+	}
+
+	var assignments []string
+	for _, line := range strings.Split(actions.Input("vars"), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			assignments = append(assignments, line)
+		}
+	}
+	vars, err := parser.ParseVars(assignments)
+	if err != nil {
+		return err
+	}
+
+	// Report invalid definitions as annotations on the label file
+	problems, err := parser.ValidateFile(file)
+	if err != nil {
+		actions.Error(os.Stdout, actions.Annotation{File: file, Title: "Invalid label file"}, err.Error())
+		return err
+	}
+	for _, p := range problems {
+		message := p.Message
+		if p.Label != "" {
+			message = fmt.Sprintf("%s: %s", p.Label, p.Message)
+		}
+		actions.Error(os.Stdout, actions.Annotation{
+			File:   file,
+			Line:   p.Line,
+			Column: p.Column,
+			Title:  "Invalid label definition",
+		}, message)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%d invalid label definition(s) in %s", len(problems), file)
+	}
+
+	report, err := newReporter(outputText, force, deleteUnmanaged)
+	if err != nil {
+		return err
+	}
+	group, err := actions.BoolInput("group", false)
+	if err != nil {
+		return err
+	}
+	if err := report.setOrder(actions.Input("sort"), group); err != nil {
+		return err
+	}
+
+	data, err := parser.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	client, diffs, err := planSync(file, data, "", repo, vars)
+	if err != nil {
+		return err
+	}
+
+	if err := report.printDiff(diffs, false); err != nil {
+		return err
+	}
+
+	counts := diff.Summary(diffs)
+	if counts.Updates > 0 && !force {
+		actions.Warning(os.Stdout, actions.Annotation{File: file}, fmt.Sprintf("%d label(s) differ from %s; set force: true to update them", counts.Updates, file))
+	}
+	if counts.Extras > 0 && !deleteUnmanaged {
+		actions.Warning(os.Stdout, actions.Annotation{}, fmt.Sprintf("%d label(s) exist in %s but not in %s", counts.Extras, repo, file))
+	}
+
+	for _, s := range diff.SuggestRenames(diffs) {
+		actions.Warning(os.Stdout, actions.Annotation{File: file}, fmt.Sprintf("%s looks like a rename of %s (%s); list %q in its aliases to rename it", s.To, s.From, s.Reason(), s.From))
+	}
+
+	var result applyResult
+	pending := diff.Pending(diffs, force, deleteUnmanaged)
+	if dryRun {
+		report.printf("\n(dry-run mode: no changes applied)\n")
+	} else if len(pending) > 0 {
+		result = applyDiffs(client, pending, report.out())
+		if err := report.printResult(result); err != nil {
+			return err
+		}
+	}
+
+	outputs := []struct {
+		name  string
+		value int
+	}{
+		{"created", result.Created},
+		{"updated", result.Updated},
+		{"deleted", result.Deleted},
+		{"failed", result.Failed},
+	}
+	for _, o := range outputs {
+		if err := actions.SetOutput(o.name, strconv.Itoa(o.value)); err != nil {
+			return err
+		}
+	}
+
+	if result.Failed > 0 {
+		return fmt.Errorf("%d label operation(s) failed", result.Failed)
+	}
+
+	return nil
+}
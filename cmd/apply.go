@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/scttfrdmn/gh-label-sync/pkg/api"
+	"github.com/scttfrdmn/gh-label-sync/pkg/diff"
+)
+
+// applyResult holds the counts of applied and failed operations, and the
+// error for each failed label
+type applyResult struct {
+	Created  int
+	Updated  int
+	Deleted  int
+	Failed   int
+	Failures map[string]error
+}
+
+// applyDiffs applies each diff to the store and returns the counts of
+// operations. Progress is written to out and failures to stderr. Matching
+// labels are ignored, so callers are expected to pass only the diffs they
+// want applied. Stores that support batching receive all changes at once.
+func applyDiffs(store api.Store, diffs []diff.LabelDiff, out io.Writer) applyResult {
+	var ops []api.Operation
+	for _, d := range diffs {
+		switch d.Type {
+		case diff.DiffTypeCreate:
+			ops = append(ops, api.Operation{Kind: api.OperationCreate, Name: d.Name, Input: labelInput(d.Desired)})
+		case diff.DiffTypeUpdate, diff.DiffTypeRename:
+			// Renames address the label by its current name
+			ops = append(ops, api.Operation{Kind: api.OperationUpdate, Name: d.Current.Name, Input: labelInput(d.Desired)})
+		case diff.DiffTypeExtra, diff.DiffTypeDelete:
+			ops = append(ops, api.Operation{Kind: api.OperationDelete, Name: d.Name})
+		}
+	}
+
+	var errs []error
+	if batcher, ok := store.(api.Batcher); ok {
+		errs = batcher.ApplyBatch(ops)
+	} else {
+		errs = make([]error, len(ops))
+		for i, op := range ops {
+			switch op.Kind {
+			case api.OperationCreate:
+				_, errs[i] = store.CreateLabel(op.Input)
+			case api.OperationUpdate:
+				_, errs[i] = store.UpdateLabel(op.Name, op.Input)
+			case api.OperationDelete:
+				errs[i] = store.DeleteLabel(op.Name)
+			}
+		}
+	}
+
+	result := applyResult{Failures: make(map[string]error)}
+	for i, op := range ops {
+		if errs[i] != nil {
+			fmt.Fprintf(os.Stderr, "  ✗ Failed to %s %s: %v\n", op.Kind, op.Name, errs[i])
+			result.Failed++
+			result.Failures[op.Name] = errs[i]
+			continue
+		}
+
+		switch op.Kind {
+		case api.OperationCreate:
+			fmt.Fprintf(out, "  ✓ Created %s\n", op.Name)
+			result.Created++
+		case api.OperationUpdate:
+			if op.Input.Name != op.Name {
+				fmt.Fprintf(out, "  ✓ Renamed %s → %s\n", op.Name, op.Input.Name)
+			} else {
+				fmt.Fprintf(out, "  ✓ Updated %s\n", op.Name)
+			}
+			result.Updated++
+		case api.OperationDelete:
+			fmt.Fprintf(out, "  ✓ Deleted %s\n", op.Name)
+			result.Deleted++
+		}
+	}
+
+	return result
+}
+
+// labelInput builds the API input for a desired label
+func labelInput(label *api.Label) api.LabelInput {
+	return api.LabelInput{
+		Name:        label.Name,
+		Color:       label.Color,
+		Description: label.Description,
+		Priority:    label.Priority,
+		Exclusive:   label.Exclusive,
+	}
+}
@@ -2,15 +2,33 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/scttfrdmn/gh-label-sync/pkg/api"
 	"github.com/scttfrdmn/gh-label-sync/pkg/diff"
-	"github.com/scttfrdmn/gh-label-sync/pkg/format"
+	"github.com/scttfrdmn/gh-label-sync/pkg/migrate"
 	"github.com/spf13/cobra"
 )
 
+// Environment variables holding per-side tokens for clone
+const (
+	sourceTokenEnv = "GH_LABEL_SYNC_SOURCE_TOKEN"
+	targetTokenEnv = "GH_LABEL_SYNC_TARGET_TOKEN"
+)
+
 var (
-	cloneForce bool
+	cloneForce          bool
+	cloneDryRun         bool
+	cloneVerbose        bool
+	cloneOutput         string
+	cloneSourceHostname string
+	cloneRenames        []string
+	cloneRenameFile     string
+	cloneReport         string
+	cloneSort           string
+	cloneGroup          bool
 )
 
 var cloneCmd = &cobra.Command{
@@ -19,16 +37,37 @@ var cloneCmd = &cobra.Command{
 	Long: `Clone all labels from a source repository to the target repository.
 
 This is equivalent to exporting labels from the source and syncing to the target.
+Either repository may include a host, so labels can be copied between
+github.com and GitHub Enterprise Server. Each side authenticates with the token
+gh has stored for its host, or with $` + sourceTokenEnv + ` and
+$` + targetTokenEnv + ` when set.
+
+Labels can be renamed on the way with --rename old=new (a trailing * on both
+sides maps a prefix) or a --rename-file containing a "renames" list of
+from/to pairs. --report writes a migration report as Markdown, or as JSON when
+the file name ends in .json.
 
 Examples:
   gh label-sync clone owner/source-repo --repo owner/target-repo
-  gh label-sync clone owner/template --repo owner/new-project --force`,
+  gh label-sync clone owner/template --repo owner/new-project --force
+  gh label-sync clone github.com/owner/template --repo ghes.example.com/org/project
+  gh label-sync clone owner/template --source-hostname github.com --hostname ghes.example.com --repo org/project
+  gh label-sync clone ghes.corp/org/repo --repo github.com/org/repo --rename "kind/*=type: *" --report migration.md`,
 	Args: cobra.ExactArgs(1),
 	RunE: runClone,
 }
 
 func init() {
 	cloneCmd.Flags().BoolVar(&cloneForce, "force", false, "Update existing labels that differ")
+	cloneCmd.Flags().BoolVar(&cloneDryRun, "dry-run", false, "Show what would change without applying")
+	cloneCmd.Flags().StringVar(&cloneSourceHostname, "source-hostname", "", "Host for a source repository given without one (default: --hostname)")
+	cloneCmd.Flags().StringArrayVar(&cloneRenames, "rename", nil, "Rename a label while cloning (old=new, repeatable)")
+	cloneCmd.Flags().StringVar(&cloneRenameFile, "rename-file", "", "YAML file of rename rules")
+	cloneCmd.Flags().StringVar(&cloneReport, "report", "", "Write a migration report to this file (.md or .json)")
+	cloneCmd.Flags().StringVar(&cloneOutput, "output", outputText, "Output format (text or markdown)")
+	cloneCmd.Flags().BoolVarP(&cloneVerbose, "verbose", "v", false, "Show matching labels and every field of changed labels")
+	cloneCmd.Flags().StringVar(&cloneSort, "sort", string(diff.OrderFile), "Order of changes (file, name, or type)")
+	cloneCmd.Flags().BoolVar(&cloneGroup, "group", false, "Group changes by label prefix")
 }
 
 func runClone(cmd *cobra.Command, args []string) error {
@@ -38,9 +77,30 @@ func runClone(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("target repository required (use --repo flag)")
 	}
 
+	report, err := newReporter(cloneOutput, cloneForce, false)
+	if err != nil {
+		return err
+	}
+	if err := report.setOrder(cloneSort, cloneGroup); err != nil {
+		return err
+	}
+
+	rules, err := cloneRenameRules()
+	if err != nil {
+		return err
+	}
+
 	// Get labels from source repository
-	fmt.Printf("Fetching labels from %s...\n", sourceRepo)
-	sourceClient, err := api.NewClient(sourceRepo)
+	report.printf("Fetching labels from %s...\n", sourceRepo)
+	sourceHostname := cloneSourceHostname
+	if sourceHostname == "" {
+		sourceHostname = hostnameFlag
+	}
+
+	sourceClient, err := newStore(sourceRepo, api.Options{
+		Hostname:  sourceHostname,
+		AuthToken: os.Getenv(sourceTokenEnv),
+	})
 	if err != nil {
 		return fmt.Errorf("failed to connect to source repo: %w", err)
 	}
@@ -54,11 +114,19 @@ func runClone(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no labels found in source repository")
 	}
 
-	fmt.Printf("Found %d label(s) in source repository\n\n", len(sourceLabels))
+	report.printf("Found %d label(s) in source repository\n\n", len(sourceLabels))
+
+	desiredLabels, sourceNames, err := migrate.Rename(sourceLabels, rules)
+	if err != nil {
+		return err
+	}
 
 	// Get labels from target repository
-	fmt.Printf("Fetching labels from %s...\n", repoFlag)
-	targetClient, err := api.NewClient(repoFlag)
+	report.printf("Fetching labels from %s...\n", repoFlag)
+	targetClient, err := newStore(repoFlag, api.Options{
+		Hostname:  hostnameFlag,
+		AuthToken: os.Getenv(targetTokenEnv),
+	})
 	if err != nil {
 		return fmt.Errorf("failed to connect to target repo: %w", err)
 	}
@@ -68,64 +136,106 @@ func runClone(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to list target labels: %w", err)
 	}
 
+	// Leave labels outside the config's include and exclude globs alone
+	desiredLabels = filterLabels(desiredLabels)
+	targetLabels = filterLabels(targetLabels)
+
 	// Compute diff
-	diffs := diff.ComputeDiff(sourceLabels, targetLabels)
+	api.StripUnsupported(targetClient, desiredLabels)
+	diffs := diff.ComputeDiff(desiredLabels, targetLabels)
 
 	// Display diff
-	fmt.Print(format.FormatDiff(diffs, false))
-	fmt.Print(format.FormatSummary(diffs, cloneForce, false))
+	if err := report.printDiff(diffs, cloneVerbose); err != nil {
+		return err
+	}
 
 	// Check if there are any changes to apply
-	_, creates, updates, _ := diff.Summary(diffs)
-	totalChanges := creates
-	if cloneForce {
-		totalChanges += updates
+	var result applyResult
+	pending := diff.Pending(diffs, cloneForce, false)
+	switch {
+	case len(pending) == 0:
+		report.printf("\n✓ All labels are already in sync\n")
+	case cloneDryRun:
+		report.printf("\n(dry-run mode: no changes applied)\n")
+	default:
+		result = applyDiffs(targetClient, pending, report.out())
+		if err := report.printResult(result); err != nil {
+			return err
+		}
 	}
 
-	if totalChanges == 0 {
-		fmt.Println("\n✓ All labels are already in sync")
-		return nil
+	if cloneReport != "" {
+		return writeMigrationReport(cloneReport, sourceRepo, repoFlag, diffs, sourceNames, result)
 	}
 
-	// Apply changes
-	created, updated := 0, 0
+	return nil
+}
+
+// cloneRenameRules collects rename rules from --rename-file and --rename, in
+// that order
+func cloneRenameRules() ([]migrate.RenameRule, error) {
+	var rules []migrate.RenameRule
+
+	if cloneRenameFile != "" {
+		fileRules, err := migrate.LoadRules(cloneRenameFile)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, fileRules...)
+	}
+
+	for _, s := range cloneRenames {
+		rule, err := migrate.ParseRule(s)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// writeMigrationReport records what happened to each source label
+func writeMigrationReport(path, sourceRepo, targetRepo string, diffs []diff.LabelDiff, sourceNames map[string]string, result applyResult) error {
+	r := &migrate.Report{Source: sourceRepo, Target: targetRepo}
 
 	for _, d := range diffs {
+		source, ok := sourceNames[d.Name]
+		if !ok {
+			// Labels that only exist in the target are left alone
+			continue
+		}
+
 		switch d.Type {
-		case diff.DiffTypeCreate:
-			input := api.LabelInput{
-				Name:        d.Desired.Name,
-				Color:       d.Desired.Color,
-				Description: d.Desired.Description,
+		case diff.DiffTypeMatch:
+			r.Add(source, d.Name, migrate.ActionUnchanged, "")
+		case diff.DiffTypeCreate, diff.DiffTypeUpdate:
+			action := migrate.ActionCreated
+			if d.Type == diff.DiffTypeUpdate {
+				action = migrate.ActionUpdated
+				if !cloneForce {
+					r.Add(source, d.Name, migrate.ActionSkipped, "differs in target (use --force to update)")
+					continue
+				}
 			}
-			_, err := targetClient.CreateLabel(input)
-			if err != nil {
-				fmt.Printf("  ✗ Failed to create %s: %v\n", d.Name, err)
+			if err, failed := result.Failures[d.Name]; failed {
+				r.Add(source, d.Name, migrate.ActionFailed, err.Error())
+			} else if cloneDryRun {
+				r.Add(source, d.Name, migrate.ActionPlanned, string(d.Type))
 			} else {
-				fmt.Printf("  ✓ Created %s\n", d.Name)
-				created++
-			}
-
-		case diff.DiffTypeUpdate:
-			if cloneForce {
-				input := api.LabelInput{
-					Name:        d.Desired.Name,
-					Color:       d.Desired.Color,
-					Description: d.Desired.Description,
-				}
-				_, err := targetClient.UpdateLabel(d.Name, input)
-				if err != nil {
-					fmt.Printf("  ✗ Failed to update %s: %v\n", d.Name, err)
-				} else {
-					fmt.Printf("  ✓ Updated %s\n", d.Name)
-					updated++
-				}
+				r.Add(source, d.Name, action, "")
 			}
 		}
 	}
 
-	fmt.Println()
-	fmt.Println(format.FormatResult(created, updated, 0))
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+	defer f.Close()
 
-	return nil
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		return r.WriteJSON(f)
+	}
+	return r.WriteMarkdown(f)
 }
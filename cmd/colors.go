@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/scttfrdmn/gh-label-sync/pkg/color"
+	"github.com/scttfrdmn/gh-label-sync/pkg/format"
+	"github.com/scttfrdmn/gh-label-sync/pkg/lint"
+	"github.com/spf13/cobra"
+)
+
+var (
+	colorsFile        string
+	colorsInputFormat string
+	colorsRemote      bool
+	colorsOutput      string
+	colorsMinContrast float64
+	colorsMinDistance float64
+)
+
+var colorsCmd = &cobra.Command{
+	Use:   "colors",
+	Short: "Check label colors for contrast and near-duplicates",
+	Long: `Check label colors for accessibility.
+
+Each label's text is checked in the color GitHub renders it in (black or
+white, chosen from the label's lightness). Labels whose WCAG contrast ratio
+is below --min-contrast are reported with the closest color of the same hue
+that has enough contrast.
+
+Labels in the same group, the prefix before ":" or "/" such as "priority",
+are also compared with each other. Colors closer than --min-distance
+(CIEDE2000 ΔE) are reported as near-duplicates.
+
+Without --file, the repository's labels are checked.
+
+Examples:
+  gh label-sync colors --file .github/labels.yml
+  gh label-sync colors --remote --repo owner/repo
+  gh label-sync colors --file labels.yml --min-contrast 7 --output json`,
+	Args: cobra.NoArgs,
+	RunE: runColors,
+}
+
+func init() {
+	colorsCmd.Flags().StringVarP(&colorsFile, "file", "f", "", "Label definition file to check (- for stdin)")
+	colorsCmd.Flags().StringVar(&colorsInputFormat, "input-format", "", "Label file format (default: detected)")
+	colorsCmd.Flags().BoolVar(&colorsRemote, "remote", false, "Check the repository's labels instead of a file")
+	colorsCmd.Flags().StringVar(&colorsOutput, "output", outputText, "Output format (text, json, or sarif)")
+	colorsCmd.Flags().Float64Var(&colorsMinContrast, "min-contrast", color.MinContrast, "Minimum WCAG contrast ratio of label text")
+	colorsCmd.Flags().Float64Var(&colorsMinDistance, "min-distance", lint.DefaultMinDistance, "Minimum color difference (ΔE) within a group")
+}
+
+func runColors(cmd *cobra.Command, args []string) error {
+	if err := checkViolationOutput(colorsOutput); err != nil {
+		return err
+	}
+	if colorsMinContrast < 1 || colorsMinContrast > 21 {
+		return fmt.Errorf("--min-contrast must be between 1 and 21")
+	}
+
+	file := colorsFile
+	if colorsRemote {
+		file = ""
+	}
+	target, err := loadLintTarget(file, colorsInputFormat)
+	if err != nil {
+		return err
+	}
+
+	violations := lint.CheckContrast(target.labels, colorsMinContrast)
+	violations = append(violations, lint.CheckNearDuplicates(target.labels, colorsMinDistance)...)
+	target.locate(violations)
+
+	rules := []format.SARIFRule{
+		{ID: lint.ContrastRule, Description: fmt.Sprintf("Label text must have a contrast ratio of at least %.1f:1", colorsMinContrast)},
+		{ID: lint.NearDuplicateRule, Description: "Labels in a group must have distinguishable colors"},
+	}
+	if err := writeViolations(colorsOutput, rules, violations); err != nil {
+		return err
+	}
+
+	if n := lint.Errors(violations); n > 0 {
+		return fmt.Errorf("%d label(s) with low contrast", n)
+	}
+	if colorsOutput == outputText && len(violations) == 0 {
+		fmt.Println("✓ All label colors are readable and distinct")
+	}
+	return nil
+}
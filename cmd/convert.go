@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/scttfrdmn/gh-label-sync/pkg/parser"
+	"github.com/spf13/cobra"
+)
+
+var (
+	convertInputFormat string
+	convertTo          string
+)
+
+var convertCmd = &cobra.Command{
+	Use:   "convert <file>",
+	Short: "Convert a label file between formats",
+	Long: `Convert a label file between this tool's YAML/JSON format and the formats
+used by github-label-sync (npm), crazy-max/ghaction-github-labeler, and Probot
+settings (.github/settings.yml).
+
+The input format is detected from the file unless --input-format is given.
+Renames and deletions carry over where the target format supports them;
+anything that cannot be represented is reported on stderr. Converting
+between yaml, json, and toml keeps the file's vars, policies, and groups.
+
+Formats: yaml, json, csv, toml, markdown (output only), github-label-sync,
+labeler, probot
+
+Examples:
+  gh label-sync convert .github/labels.json --to yaml > labels.yml
+  gh label-sync convert .github/settings.yml --to yaml > labels.yml
+  gh label-sync convert labels.yml --to labeler > .github/labels.yml
+  gh label-sync convert labels.yml --to markdown > LABELS.md`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConvert,
+}
+
+func init() {
+	convertCmd.Flags().StringVar(&convertInputFormat, "input-format", "", "Input file format (default: detected)")
+	convertCmd.Flags().StringVar(&convertTo, "to", string(parser.FormatYAML), "Output format")
+}
+
+func runConvert(cmd *cobra.Command, args []string) error {
+	to, err := parser.ParseFormat(convertTo)
+	if err != nil {
+		return err
+	}
+
+	var from parser.Format
+	if convertInputFormat != "" {
+		from, err = parser.ParseFormat(convertInputFormat)
+		if err != nil {
+			return err
+		}
+	}
+
+	data, err := parser.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	// Write to a buffer, so warnings come first and a failed conversion
+	// writes nothing
+	var buf bytes.Buffer
+	warnings, err := parser.Convert(&buf, args[0], data, from, to)
+	if err != nil {
+		return err
+	}
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "⚠ %s\n", w)
+	}
+
+	_, err = os.Stdout.Write(buf.Bytes())
+	return err
+}
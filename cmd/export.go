@@ -1,7 +1,10 @@
 package cmd
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 
 	"github.com/scttfrdmn/gh-label-sync/pkg/api"
@@ -11,26 +14,46 @@ import (
 
 var (
 	exportFormat string
+	exportUpdate string
 )
 
 var exportCmd = &cobra.Command{
 	Use:   "export",
-	Short: "Export labels to YAML or JSON",
-	Long: `Export repository labels to YAML or JSON format.
+	Short: "Export labels to a file format",
+	Long: `Export repository labels to YAML, JSON, CSV, TOML, or a Markdown table.
+
+The Markdown table shows each label as a badge, for use in a README or
+contributing guide. The formats of other label tools (github-label-sync,
+labeler, probot) can also be written.
+
+With --update, labels are merged into an existing YAML file instead of
+written to stdout. Comments, ordering, and grouping in the file are kept:
+only values that differ are changed, and labels missing from the file are
+appended. Labels that are only in the file are left alone.
 
 Examples:
   gh label-sync export > labels.yml
   gh label-sync export --format json > labels.json
-  gh label-sync export --repo owner/repo > labels.yml`,
+  gh label-sync export --format csv > labels.csv
+  gh label-sync export --format toml > labels.toml
+  gh label-sync export --format markdown > LABELS.md
+  gh label-sync export --repo owner/repo > labels.yml
+  gh label-sync export --update .github/labels.yml`,
 	RunE: runExport,
 }
 
 func init() {
-	exportCmd.Flags().StringVar(&exportFormat, "format", "yaml", "Output format (yaml or json)")
+	exportCmd.Flags().StringVar(&exportFormat, "format", "yaml", "Output format (yaml, json, csv, toml, or markdown)")
+	exportCmd.Flags().StringVar(&exportUpdate, "update", "", "Merge labels into this YAML file, keeping its comments and order")
 }
 
 func runExport(cmd *cobra.Command, args []string) error {
-	client, err := api.NewClient(repoFlag)
+	format, err := parser.ParseFormat(exportFormat)
+	if err != nil {
+		return err
+	}
+
+	client, err := newStore(repoFlag, api.Options{Hostname: hostnameFlag})
 	if err != nil {
 		return err
 	}
@@ -39,19 +62,49 @@ func runExport(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	labels = filterLabels(labels)
+
+	if exportUpdate != "" {
+		if format != parser.FormatYAML {
+			return fmt.Errorf("--update only supports YAML files")
+		}
+		return updateLabelFile(exportUpdate, labels)
+	}
 
-	switch exportFormat {
-	case "yaml", "yml":
-		err = parser.WriteYAML(os.Stdout, labels)
-	case "json":
-		err = parser.WriteJSON(os.Stdout, labels)
-	default:
-		return fmt.Errorf("unsupported format: %s (use yaml or json)", exportFormat)
+	return parser.WriteFormat(os.Stdout, labels, format)
+}
+
+// updateLabelFile merges labels into a YAML label file, creating it if needed
+func updateLabelFile(path string, labels []api.Label) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		var buf bytes.Buffer
+		if err := parser.WriteYAML(&buf, labels); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		fmt.Fprintf(os.Stderr, "✓ Wrote %d label(s) to %s\n", len(labels), path)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
 	}
 
+	merged, result, err := parser.MergeYAML(data, labels)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to update %s: %w", path, err)
+	}
+
+	if result.Updated+result.Added == 0 {
+		fmt.Fprintf(os.Stderr, "✓ %s is up to date\n", path)
+		return nil
 	}
 
+	if err := os.WriteFile(path, merged, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	fmt.Fprintf(os.Stderr, "✓ Updated %s (%d changed, %d added)\n", path, result.Updated, result.Added)
 	return nil
 }
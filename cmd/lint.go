@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+
+	"github.com/scttfrdmn/gh-label-sync/pkg/api"
+	"github.com/scttfrdmn/gh-label-sync/pkg/format"
+	"github.com/scttfrdmn/gh-label-sync/pkg/lint"
+	"github.com/scttfrdmn/gh-label-sync/pkg/parser"
+	"github.com/spf13/cobra"
+)
+
+const (
+	outputJSON  = "json"
+	outputSARIF = "sarif"
+)
+
+// validLabelRule is the rule ID of label definition problems, such as
+// malformed colors, reported alongside policy violations
+const validLabelRule = "valid-label"
+
+var (
+	lintFile        string
+	lintInputFormat string
+	lintPolicy      string
+	lintRemote      bool
+	lintOutput      string
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check labels against policy rules",
+	Long: `Check a label file, or a repository's labels, against the rules in a
+policy file (default: ` + lint.DefaultPolicyFile + `).
+
+Rules select labels by name glob and can require names to match a regular
+expression, colors to come from a palette, or descriptions to be present,
+and can limit how many labels they match. The policy can also limit the
+total number of labels.
+
+Label files are also checked for invalid definitions (missing names,
+malformed colors, duplicates, over-long fields). Without a policy file, only
+those checks run. With --output sarif, the results can be uploaded to GitHub
+code scanning to show them inline on pull requests.
+
+The command fails if any violation has error severity.
+
+Examples:
+  gh label-sync lint --file .github/labels.yml
+  gh label-sync lint --file labels.yml --policy org-policy.yml --output json
+  gh label-sync lint --file .github/labels.yml --output sarif > labels.sarif
+  gh label-sync lint --remote --repo owner/repo`,
+	Args: cobra.NoArgs,
+	RunE: runLint,
+}
+
+func init() {
+	lintCmd.Flags().StringVarP(&lintFile, "file", "f", "", "Label definition file to lint (- for stdin)")
+	lintCmd.Flags().StringVar(&lintInputFormat, "input-format", "", "Label file format (default: detected)")
+	lintCmd.Flags().StringVar(&lintPolicy, "policy", lint.DefaultPolicyFile, "Policy file with the rules to check")
+	lintCmd.Flags().BoolVar(&lintRemote, "remote", false, "Lint the repository's labels instead of a file")
+	lintCmd.Flags().StringVar(&lintOutput, "output", outputText, "Output format (text, json, or sarif)")
+}
+
+func runLint(cmd *cobra.Command, args []string) error {
+	if err := checkViolationOutput(lintOutput); err != nil {
+		return err
+	}
+
+	// The default policy file is optional when linting a file
+	linting := lintFile != "" && !lintRemote
+	policy, err := lint.LoadPolicy(lintPolicy)
+	if errors.Is(err, fs.ErrNotExist) && linting && !cmd.Flags().Changed("policy") {
+		policy, err = &lint.Policy{}, nil
+	}
+	if err != nil {
+		return err
+	}
+
+	file := lintFile
+	if !linting {
+		file = ""
+	}
+	target, err := loadLintTarget(file, lintInputFormat)
+	if err != nil {
+		return err
+	}
+
+	var violations []lint.Violation
+	if target.file != "" {
+		for _, p := range parser.Validate(target.labels) {
+			violations = append(violations, lint.Violation{
+				Rule:     validLabelRule,
+				Severity: lint.SeverityError,
+				Label:    p.Label,
+				Index:    p.Index,
+				Message:  p.Message,
+			})
+		}
+	}
+	violations = append(violations, policy.Lint(target.labels)...)
+	target.locate(violations)
+
+	if err := writeViolations(lintOutput, sarifRules(policy), violations); err != nil {
+		return err
+	}
+
+	if n := lint.Errors(violations); n > 0 {
+		return fmt.Errorf("%d lint error(s)", n)
+	}
+	if lintOutput == outputText && len(violations) == 0 {
+		fmt.Println("✓ All labels follow the policy")
+	}
+	return nil
+}
+
+// lintTarget holds the labels a check runs on and, when they come from a
+// file, where each is defined
+type lintTarget struct {
+	file      string
+	labels    []api.Label
+	positions []parser.Position
+}
+
+// loadLintTarget reads the labels of a file or, if file is empty, of the
+// repository
+func loadLintTarget(file, inputFormat string) (*lintTarget, error) {
+	if file == "" {
+		client, err := newStore(repoFlag, api.Options{Hostname: hostnameFlag})
+		if err != nil {
+			return nil, err
+		}
+		labels, err := client.ListLabels()
+		if err != nil {
+			return nil, err
+		}
+		return &lintTarget{labels: filterLabels(labels)}, nil
+	}
+
+	var format parser.Format
+	if inputFormat != "" {
+		f, err := parser.ParseFormat(inputFormat)
+		if err != nil {
+			return nil, err
+		}
+		format = f
+	}
+
+	labels, err := parser.ParseFileFormat(file, format)
+	if err != nil {
+		return nil, err
+	}
+
+	target := &lintTarget{file: file, labels: labels}
+	if file != "-" {
+		target.positions, err = parser.LabelPositions(file)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return target, nil
+}
+
+// locate orders violations by label and fills in their file positions
+func (t *lintTarget) locate(violations []lint.Violation) {
+	slices.SortStableFunc(violations, func(a, b lint.Violation) int { return a.Index - b.Index })
+
+	if t.file == "" {
+		return
+	}
+	for i := range violations {
+		violations[i].File = t.file
+		if idx := violations[i].Index; idx >= 0 && idx < len(t.positions) {
+			violations[i].Line = t.positions[idx].Line
+			violations[i].Column = t.positions[idx].Column
+		}
+	}
+}
+
+// checkViolationOutput validates an --output value for writeViolations
+func checkViolationOutput(output string) error {
+	switch output {
+	case outputText, outputJSON, outputSARIF:
+		return nil
+	}
+	return fmt.Errorf("unsupported output: %s (use text, json, or sarif)", output)
+}
+
+// writeViolations writes violations in the given output format
+func writeViolations(output string, rules []format.SARIFRule, violations []lint.Violation) error {
+	switch output {
+	case outputJSON:
+		return lint.WriteJSON(os.Stdout, violations)
+	case outputSARIF:
+		return format.WriteSARIF(os.Stdout, rules, sarifResults(violations))
+	default:
+		return lint.WriteText(os.Stdout, violations)
+	}
+}
+
+// sarifRules describes the checks lint runs, for SARIF output
+func sarifRules(policy *lint.Policy) []format.SARIFRule {
+	rules := []format.SARIFRule{{ID: validLabelRule, Description: "Label definitions must be valid"}}
+	if policy.MaxLabels > 0 {
+		rules = append(rules, format.SARIFRule{
+			ID:          lint.MaxLabelsRule,
+			Description: fmt.Sprintf("At most %d labels may be defined", policy.MaxLabels),
+		})
+	}
+	for _, r := range policy.Rules {
+		rules = append(rules, format.SARIFRule{ID: r.ID, Description: r.Describe()})
+	}
+	return rules
+}
+
+// sarifResults converts violations to SARIF results, naming the label and
+// any suggested color in each message
+func sarifResults(violations []lint.Violation) []format.SARIFResult {
+	results := make([]format.SARIFResult, len(violations))
+	for i, v := range violations {
+		message := v.Message
+		if v.Label != "" {
+			message = fmt.Sprintf("%s: %s", v.Label, v.Message)
+		}
+		if v.Suggestion != "" {
+			message += fmt.Sprintf(" (try #%s)", v.Suggestion)
+		}
+		results[i] = format.SARIFResult{
+			RuleID:  v.Rule,
+			Level:   string(v.Severity),
+			Message: message,
+			File:    v.File,
+			Line:    v.Line,
+			Column:  v.Column,
+		}
+	}
+	return results
+}
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/scttfrdmn/gh-label-sync/pkg/api"
+	"github.com/scttfrdmn/gh-label-sync/pkg/color"
+	"github.com/scttfrdmn/gh-label-sync/pkg/format"
+	"github.com/scttfrdmn/gh-label-sync/pkg/parser"
+	"github.com/scttfrdmn/gh-label-sync/pkg/prompt"
+	"github.com/spf13/cobra"
+)
+
+var (
+	paletteFile        string
+	paletteMatch       string
+	paletteGradient    []string
+	paletteLightness   float64
+	paletteChroma      float64
+	paletteHue         float64
+	paletteMinContrast float64
+	paletteDryRun      bool
+	paletteYes         bool
+)
+
+var paletteCmd = &cobra.Command{
+	Use:   "palette",
+	Short: "Generate colors for a group of labels",
+	Long: `Generate colors for the labels matching --match and write them into the
+label file.
+
+By default the labels get perceptually distinct colors: hues evenly spaced
+around the OKLCH color wheel at the same lightness and chroma, so no color
+stands out more than the others. With --gradient FROM,TO the labels get
+colors stepping evenly from one color to the other, in file order, which
+suits ordered groups such as priority levels.
+
+Generated colors are darkened or lightened where needed so label text has
+at least --min-contrast (0 to disable).
+
+A preview is shown before the file is written. YAML files are edited in
+place, keeping comments, ordering, and grouping; other formats are rewritten.
+
+Examples:
+  gh label-sync palette --file labels.yml --match "area:*"
+  gh label-sync palette --file labels.yml --match "priority:*" --gradient b60205,fbca04
+  gh label-sync palette --file labels.yml --match "type:*" --hue 200 --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: runPalette,
+}
+
+func init() {
+	paletteCmd.Flags().StringVarP(&paletteFile, "file", "f", "", "Label definition file to update")
+	paletteCmd.Flags().StringVar(&paletteMatch, "match", "", "Glob selecting the labels to color, e.g. \"priority:*\"")
+	paletteCmd.Flags().StringSliceVar(&paletteGradient, "gradient", nil, "Step from one color to another (FROM,TO)")
+	paletteCmd.Flags().Float64Var(&paletteLightness, "lightness", 0.6, "OKLCH lightness of distinct colors (0-1)")
+	paletteCmd.Flags().Float64Var(&paletteChroma, "chroma", 0.15, "OKLCH chroma of distinct colors (0-0.37)")
+	paletteCmd.Flags().Float64Var(&paletteHue, "hue", 25, "Hue of the first distinct color, in degrees")
+	paletteCmd.Flags().Float64Var(&paletteMinContrast, "min-contrast", color.MinContrast, "Minimum contrast ratio of label text (0 to disable)")
+	paletteCmd.Flags().BoolVar(&paletteDryRun, "dry-run", false, "Show the colors without writing the file")
+	paletteCmd.Flags().BoolVarP(&paletteYes, "yes", "y", false, "Skip confirmation prompt")
+	paletteCmd.MarkFlagRequired("file")
+	paletteCmd.MarkFlagRequired("match")
+}
+
+func runPalette(cmd *cobra.Command, args []string) error {
+	if paletteFile == "-" {
+		return fmt.Errorf("palette needs a file to write to, not stdin")
+	}
+	if err := api.ValidateGlob(paletteMatch); err != nil {
+		return fmt.Errorf("invalid --match pattern %q", paletteMatch)
+	}
+
+	data, err := os.ReadFile(paletteFile)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	fileFormat := parser.DetectFormat(paletteFile, data)
+
+	fileLabels, err := parser.ParseFile(paletteFile)
+	if err != nil {
+		return err
+	}
+
+	var group []api.Label
+	for _, l := range fileLabels {
+		// Templated colors are set per repository, so they are kept
+		if api.MatchGlob(paletteMatch, l.Name) && !l.Delete && !parser.IsTemplate(l.Color) {
+			group = append(group, l)
+		}
+	}
+	if len(group) == 0 {
+		return fmt.Errorf("no labels in %s match %q", paletteFile, paletteMatch)
+	}
+
+	colors, err := paletteColors(len(group))
+	if err != nil {
+		return err
+	}
+
+	style := format.DetectStyle()
+	var changed []api.Label
+	for i, l := range group {
+		hex := api.NormalizeColor(colors[i].Hex())
+		if api.NormalizeColor(l.Color) == hex {
+			fmt.Printf("  %s %s - keeps %s\n", style.Green("✓"), style.Badge(l.Name, hex), style.Swatch(hex))
+			continue
+		}
+		fmt.Printf("  %s %s - color: %s → %s\n", style.Yellow("~"), style.Badge(l.Name, hex), style.Swatch(l.Color), style.Swatch(hex))
+		l.Color = hex
+		changed = append(changed, l)
+	}
+
+	if len(changed) == 0 {
+		fmt.Printf("\n✓ %s already uses this palette\n", paletteFile)
+		return nil
+	}
+
+	updated, _, err := parser.Merge(data, fileFormat, changed)
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", paletteFile, err)
+	}
+
+	if paletteDryRun {
+		fmt.Println("\n(dry-run mode: file not changed)")
+		return nil
+	}
+
+	if !paletteYes {
+		fmt.Println()
+		ok, err := prompt.Confirm(fmt.Sprintf("? Update %s?", paletteFile))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Println("Cancelled.")
+			return nil
+		}
+	}
+
+	if err := os.WriteFile(paletteFile, updated, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", paletteFile, err)
+	}
+
+	fmt.Printf("\n✓ Updated %s (%d label(s))\n", paletteFile, len(changed))
+	return nil
+}
+
+// paletteColors generates n colors from the palette flags
+func paletteColors(n int) ([]color.RGB, error) {
+	var colors []color.RGB
+	if paletteGradient != nil {
+		if len(paletteGradient) != 2 {
+			return nil, fmt.Errorf("--gradient needs two colors (FROM,TO)")
+		}
+		from, err := color.Parse(api.NormalizeColor(paletteGradient[0]))
+		if err != nil {
+			return nil, err
+		}
+		to, err := color.Parse(api.NormalizeColor(paletteGradient[1]))
+		if err != nil {
+			return nil, err
+		}
+		colors = color.Gradient(from, to, n)
+	} else {
+		if paletteLightness < 0 || paletteLightness > 1 {
+			return nil, fmt.Errorf("--lightness must be between 0 and 1")
+		}
+		if paletteChroma < 0 {
+			return nil, fmt.Errorf("--chroma must not be negative")
+		}
+		colors = color.Distinct(n, paletteLightness, paletteChroma, paletteHue)
+	}
+
+	if paletteMinContrast > 0 {
+		for i, c := range colors {
+			colors[i] = c.Accessible(paletteMinContrast)
+		}
+	}
+	return colors, nil
+}
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/scttfrdmn/gh-label-sync/pkg/api"
+	"github.com/scttfrdmn/gh-label-sync/pkg/diff"
+	"github.com/scttfrdmn/gh-label-sync/pkg/format"
+	"github.com/scttfrdmn/gh-label-sync/pkg/parser"
+	"github.com/scttfrdmn/gh-label-sync/pkg/prompt"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pullFile   string
+	pullAddNew bool
+	pullDryRun bool
+	pullYes    bool
+)
+
+var pullCmd = &cobra.Command{
+	Use:   "pull",
+	Short: "Update a label file from the repository",
+	Long: `Pull label changes made in the repository back into a label file.
+
+This is sync in reverse: labels whose color or description was changed in
+the repository are updated in the file, and with --add-new, labels that only
+exist in the repository are appended. Labels only in the file are kept.
+
+A preview is shown before the file is written. YAML files, including other
+tools' formats and Probot settings, are edited in place, keeping comments,
+ordering, grouping, and other settings; JSON, TOML, and CSV are rewritten.
+
+Examples:
+  gh label-sync pull --file .github/labels.yml
+  gh label-sync pull --file labels.yml --add-new
+  gh label-sync pull --file labels.yml --dry-run`,
+	RunE: runPull,
+}
+
+func init() {
+	pullCmd.Flags().StringVarP(&pullFile, "file", "f", "", "Label definition file to update")
+	pullCmd.Flags().BoolVar(&pullAddNew, "add-new", false, "Add labels that only exist in the repository")
+	pullCmd.Flags().BoolVar(&pullDryRun, "dry-run", false, "Show what would change without writing the file")
+	pullCmd.Flags().BoolVarP(&pullYes, "yes", "y", false, "Skip confirmation prompt")
+	pullCmd.MarkFlagRequired("file")
+}
+
+func runPull(cmd *cobra.Command, args []string) error {
+	if pullFile == "-" {
+		return fmt.Errorf("pull needs a file to write to, not stdin")
+	}
+
+	data, err := os.ReadFile(pullFile)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	fileFormat := parser.DetectFormat(pullFile, data)
+
+	// Templates are kept as written, so they are never replaced
+	labelFile, err := parser.ParseLabelFile(pullFile, "", nil)
+	if err != nil {
+		return err
+	}
+
+	client, err := newStore(repoFlag, api.Options{Hostname: hostnameFlag})
+	if err != nil {
+		return err
+	}
+
+	fileLabels, excluded, err := matchConditions(labelFile.Labels, client)
+	if err != nil {
+		return err
+	}
+
+	repoLabels, err := client.ListLabels()
+	if err != nil {
+		return err
+	}
+	fileLabels = filterLabels(fileLabels)
+	repoLabels = filterLabels(repoLabels)
+
+	// The file is the desired side, so differences are read in reverse.
+	// Labels the file defines for other repositories are not new.
+	diffs := skipTemplates(diff.ComputeDiff(fileLabels, repoLabels), fileLabels)
+	diff.MarkExcluded(diffs, excluded)
+
+	var pulled []api.Label
+	for _, d := range diffs {
+		switch {
+		case d.Excluded:
+			continue
+		case d.Type == diff.DiffTypeUpdate && !diff.Blocked(d):
+			// The file's condition tells apart definitions of the same name
+			label := *d.Current
+			label.When = d.Desired.When
+			pulled = append(pulled, label)
+		case d.Type == diff.DiffTypeExtra && pullAddNew:
+			pulled = append(pulled, *d.Current)
+		}
+	}
+
+	fmt.Println("Analyzing labels...")
+	fmt.Print(format.FormatPull(diffs, pullAddNew, format.DetectStyle()))
+	fmt.Print(format.FormatPullSummary(diffs, pullAddNew))
+
+	if len(pulled) == 0 {
+		fmt.Printf("\n✓ %s is up to date\n", pullFile)
+		return nil
+	}
+
+	updated, _, err := parser.Merge(data, fileFormat, pulled)
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", pullFile, err)
+	}
+
+	if pullDryRun {
+		fmt.Println("\n(dry-run mode: file not changed)")
+		return nil
+	}
+
+	if !pullYes {
+		fmt.Println()
+		ok, err := prompt.Confirm(fmt.Sprintf("? Update %s?", pullFile))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Println("Cancelled.")
+			return nil
+		}
+	}
+
+	if err := os.WriteFile(pullFile, updated, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", pullFile, err)
+	}
+
+	fmt.Printf("\n✓ Updated %s (%d label(s))\n", pullFile, len(pulled))
+	return nil
+}
+
+// matchConditions splits the file's labels into those that apply to the
+// repository and those whose conditions it does not meet. The repository is
+// only looked up if a label has a condition.
+func matchConditions(labels []api.Label, client api.Store) ([]api.Label, []api.Label, error) {
+	var repo *api.Repository
+	var kept, excluded []api.Label
+	for _, l := range labels {
+		if l.When != nil {
+			if repo == nil {
+				r, err := api.Describe(client)
+				if err != nil {
+					return nil, nil, err
+				}
+				repo = r
+			}
+			if !l.When.Matches(repo) {
+				excluded = append(excluded, l)
+				continue
+			}
+		}
+		kept = append(kept, l)
+	}
+	return kept, excluded, nil
+}
+
+// skipTemplates leaves the file's ${name} templates out of a pull. Fields
+// written as templates are not compared, and repository labels that a
+// templated name could expand to are not offered as new labels.
+func skipTemplates(diffs []diff.LabelDiff, fileLabels []api.Label) []diff.LabelDiff {
+	var templates []string
+	for _, l := range fileLabels {
+		if parser.IsTemplate(l.Name) {
+			templates = append(templates, l.Name)
+		}
+	}
+
+	kept := diffs[:0]
+	for _, d := range diffs {
+		switch d.Type {
+		case diff.DiffTypeUpdate:
+			if parser.IsTemplate(d.Desired.Color) {
+				d.ColorChange = false
+			}
+			if parser.IsTemplate(d.Desired.Description) {
+				d.DescChange = false
+			}
+			if !d.ColorChange && !d.DescChange && !d.PriorityChange && !d.ExclusiveChange {
+				d.Type = diff.DiffTypeMatch
+			}
+		case diff.DiffTypeExtra:
+			if slices.ContainsFunc(templates, func(t string) bool { return parser.MatchTemplate(t, d.Name) }) {
+				continue
+			}
+		}
+		kept = append(kept, d)
+	}
+	return kept
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/scttfrdmn/gh-label-sync/pkg/actions"
+	"github.com/scttfrdmn/gh-label-sync/pkg/diff"
+	"github.com/scttfrdmn/gh-label-sync/pkg/format"
+)
+
+const (
+	outputText     = "text"
+	outputMarkdown = "markdown"
+)
+
+// reporter writes the diff and results of a sync in the selected output
+// format, and mirrors them to the job summary when running in GitHub Actions
+type reporter struct {
+	markdown        bool
+	force           bool
+	deleteUnmanaged bool
+	summaryPath     string
+
+	// order and group decide how diffs are sorted for display
+	order diff.Order
+	group bool
+}
+
+// newReporter creates a reporter for the given --output value
+func newReporter(output string, force, deleteUnmanaged bool) (*reporter, error) {
+	r := &reporter{
+		force:           force,
+		deleteUnmanaged: deleteUnmanaged,
+		order:           diff.OrderFile,
+	}
+
+	switch output {
+	case outputText:
+	case outputMarkdown:
+		r.markdown = true
+	default:
+		return nil, fmt.Errorf("unsupported output: %s (use text or markdown)", output)
+	}
+
+	if actions.IsActions() {
+		r.summaryPath = os.Getenv("GITHUB_STEP_SUMMARY")
+	}
+
+	return r, nil
+}
+
+// out returns the writer for progress messages. In markdown mode stdout is
+// reserved for the report, so messages go to stderr.
+func (r *reporter) out() io.Writer {
+	if r.markdown {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// printf writes a progress message
+func (r *reporter) printf(format string, args ...any) {
+	fmt.Fprintf(r.out(), format, args...)
+}
+
+// setOrder sets how diffs are sorted from --sort and --group values
+func (r *reporter) setOrder(order string, group bool) error {
+	o, err := diff.ParseOrder(order)
+	if err != nil {
+		return err
+	}
+	r.order, r.group = o, group
+	return nil
+}
+
+// printDiff sorts the diffs in place and writes them with a summary
+func (r *reporter) printDiff(diffs []diff.LabelDiff, verbose bool) error {
+	diff.Sort(diffs, r.order, r.group)
+	md := format.FormatMarkdown(diffs, r.force, r.deleteUnmanaged, r.group)
+
+	if r.markdown {
+		fmt.Print(md)
+	} else {
+		if r.group {
+			fmt.Print(format.FormatGroupedDiff(diffs, verbose, format.DetectStyle()))
+		} else {
+			fmt.Print(format.FormatDiff(diffs, verbose, format.DetectStyle()))
+		}
+		fmt.Print(format.FormatSummary(diffs, r.force, r.deleteUnmanaged))
+	}
+
+	return r.appendSummary(md)
+}
+
+// printRepo writes the heading of a repository when syncing several, as a
+// Markdown heading in markdown mode and in the job summary
+func (r *reporter) printRepo(repo string, first bool) error {
+	md := fmt.Sprintf("## %s\n\n", repo)
+
+	if r.markdown {
+		fmt.Print(md)
+	} else {
+		if !first {
+			fmt.Println()
+		}
+		fmt.Printf("== %s ==\n", repo)
+	}
+
+	return r.appendSummary(md)
+}
+
+// printResult writes the counts of applied changes
+func (r *reporter) printResult(result applyResult) error {
+	md := format.FormatMarkdownResult(result.Created, result.Updated, result.Deleted)
+
+	if r.markdown {
+		fmt.Print(md)
+	} else {
+		fmt.Println()
+		fmt.Println(format.FormatResult(result.Created, result.Updated, result.Deleted))
+	}
+
+	return r.appendSummary(md)
+}
+
+// appendSummary appends Markdown to $GITHUB_STEP_SUMMARY, if set
+func (r *reporter) appendSummary(md string) error {
+	if r.summaryPath == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(r.summaryPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open job summary: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := io.WriteString(f, md); err != nil {
+		return fmt.Errorf("failed to write job summary: %w", err)
+	}
+
+	return nil
+}
@@ -1,11 +1,21 @@
 package cmd
 
 import (
+	"fmt"
+
+	"github.com/scttfrdmn/gh-label-sync/pkg/config"
 	"github.com/spf13/cobra"
 )
 
 var (
-	repoFlag string
+	repoFlag     string
+	hostnameFlag string
+	apiFlag      string
+	providerFlag string
+	profileFlag  string
+
+	// settings are the config file settings for the selected profile
+	settings config.Settings
 )
 
 var rootCmd = &cobra.Command{
@@ -17,8 +27,9 @@ Examples:
   gh label-sync export > labels.yml
   gh label-sync sync --file labels.yml
   gh label-sync clone source/repo --repo target/repo`,
-	SilenceUsage:  true,
-	SilenceErrors: true,
+	SilenceUsage:      true,
+	SilenceErrors:     true,
+	PersistentPreRunE: loadConfig,
 }
 
 func Execute() error {
@@ -27,10 +38,49 @@ func Execute() error {
 
 func init() {
 	// Global flags
-	rootCmd.PersistentFlags().StringVarP(&repoFlag, "repo", "R", "", "Repository (owner/repo)")
+	rootCmd.PersistentFlags().StringVarP(&repoFlag, "repo", "R", "", "Repository ([HOST/]owner/repo)")
+	rootCmd.PersistentFlags().StringVar(&hostnameFlag, "hostname", "", "API host for repositories given without one (GitHub Enterprise Server, GitLab, or Gitea)")
+	rootCmd.PersistentFlags().StringVar(&apiFlag, "api", apiREST, "API backend (rest or graphql)")
+	rootCmd.PersistentFlags().StringVar(&providerFlag, "provider", providerGitHub, "Label provider (github, gitlab, or gitea)")
+	rootCmd.PersistentFlags().StringVar(&profileFlag, "profile", "", "Config profile to use (from "+config.FileName+")")
 
 	// Add subcommands
 	rootCmd.AddCommand(exportCmd)
 	rootCmd.AddCommand(syncCmd)
 	rootCmd.AddCommand(cloneCmd)
+	rootCmd.AddCommand(actionCmd)
+	rootCmd.AddCommand(convertCmd)
+	rootCmd.AddCommand(pullCmd)
+	rootCmd.AddCommand(lintCmd)
+	rootCmd.AddCommand(colorsCmd)
+	rootCmd.AddCommand(paletteCmd)
+}
+
+// loadConfig reads the config files and fills in flags that were not given
+// on the command line. Precedence is flags, then the selected profile, then
+// config defaults (repository-local over user-level), then built-in defaults.
+func loadConfig(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	settings, err = cfg.Resolve(profileFlag)
+	if err != nil {
+		return err
+	}
+
+	for name, value := range settings.FlagValues() {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || flag.Changed {
+			continue
+		}
+		if err := flag.Value.Set(value); err != nil {
+			return fmt.Errorf("invalid %s in config: %w", name, err)
+		}
+		// Required flags are satisfied by the config
+		flag.Changed = true
+	}
+
+	return nil
 }
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/scttfrdmn/gh-label-sync/pkg/api"
+	"github.com/scttfrdmn/gh-label-sync/pkg/gitea"
+	"github.com/scttfrdmn/gh-label-sync/pkg/gitlab"
+)
+
+const (
+	apiREST    = "rest"
+	apiGraphQL = "graphql"
+)
+
+const (
+	providerGitHub = "github"
+	providerGitLab = "gitlab"
+	providerGitea  = "gitea"
+
+	// providerForgejo is an alias for gitea, since Forgejo serves the same API
+	providerForgejo = "forgejo"
+)
+
+// newStore creates a label store for the repository using the provider
+// selected with --provider and, for GitHub, the backend selected with --api
+func newStore(repo string, opts api.Options) (api.Store, error) {
+	switch providerFlag {
+	case providerGitHub:
+	case providerGitLab:
+		return gitlab.NewClient(repo, gitlab.Options{
+			Hostname:  opts.Hostname,
+			Token:     opts.AuthToken,
+			Transport: opts.Transport,
+		})
+	case providerGitea, providerForgejo:
+		return gitea.NewClient(repo, gitea.Options{
+			Hostname:  opts.Hostname,
+			Token:     opts.AuthToken,
+			Transport: opts.Transport,
+		})
+	default:
+		return nil, fmt.Errorf("unsupported provider: %s (use github, gitlab, or gitea)", providerFlag)
+	}
+
+	switch apiFlag {
+	case apiREST:
+		return api.NewClient(repo, opts)
+	case apiGraphQL:
+		return api.NewGraphQLClient(repo, opts)
+	default:
+		return nil, fmt.Errorf("unsupported api: %s (use rest or graphql)", apiFlag)
+	}
+}
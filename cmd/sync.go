@@ -1,24 +1,32 @@
 package cmd
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
 	"os"
-	"strings"
 
 	"github.com/scttfrdmn/gh-label-sync/pkg/api"
 	"github.com/scttfrdmn/gh-label-sync/pkg/diff"
 	"github.com/scttfrdmn/gh-label-sync/pkg/format"
 	"github.com/scttfrdmn/gh-label-sync/pkg/parser"
+	"github.com/scttfrdmn/gh-label-sync/pkg/prompt"
 	"github.com/spf13/cobra"
 )
 
 var (
-	syncFile           string
-	syncDryRun         bool
-	syncForce          bool
+	syncFile            string
+	syncDryRun          bool
+	syncForce           bool
 	syncDeleteUnmanaged bool
-	syncYes            bool
+	syncYes             bool
+	syncInteractive     bool
+	syncVerbose         bool
+	syncOutput          string
+	syncInputFormat     string
+	syncVars            []string
+	syncAcceptRenames   bool
+	syncSort            string
+	syncGroup           bool
 )
 
 var syncCmd = &cobra.Command{
@@ -26,6 +34,26 @@ var syncCmd = &cobra.Command{
 	Short: "Sync labels from a file",
 	Long: `Synchronize repository labels from a YAML, JSON, or CSV file.
 
+Label files from github-label-sync, ghaction-github-labeler, and Probot
+settings are detected automatically, or can be named with --input-format.
+Renames (aliases, from_name, new_name) and deletions in those files are
+always applied.
+
+Label files may refer to variables as ${name}. Variables come from --var,
+then the file's vars block; ${env.NAME} reads an environment variable, and
+${repo.owner}, ${repo.name}, and ${repo.topics} describe the target
+repository.
+
+A label to be created that looks like an unmanaged label under a new name
+(a similar name, or the same color and description) is offered as a rename,
+which keeps the label on its issues. Use --accept-renames to accept every
+suggestion without asking.
+
+Changes are listed in file order, followed by repository-only labels by
+name, so the same inputs always produce the same output. Use --sort name or
+--sort type to reorder them, and --group to list them under each label
+prefix ("area:", "kind/").
+
 By default, this command:
 - Creates missing labels
 - Skips labels that differ (use --force to update)
@@ -35,135 +63,259 @@ Examples:
   gh label-sync sync --file labels.yml
   gh label-sync sync --file labels.json --force
   gh label-sync sync --file labels.yml --dry-run
-  gh label-sync sync --file labels.csv --delete-unmanaged --yes`,
+  gh label-sync sync --file labels.yml --interactive
+  gh label-sync sync --file labels.yml --dry-run --output markdown > plan.md
+  gh label-sync sync --file labels.yml --dry-run --sort type --group
+  gh label-sync sync --file labels.csv --delete-unmanaged --yes
+  gh label-sync sync --file labels.yml --var service=billing
+  gh label-sync export --repo owner/template --format json | gh label-sync sync --file -`,
 	RunE: runSync,
 }
 
 func init() {
-	syncCmd.Flags().StringVarP(&syncFile, "file", "f", "", "Label definition file (YAML, JSON, or CSV)")
+	syncCmd.Flags().StringVarP(&syncFile, "file", "f", "", "Label definition file (YAML, JSON, CSV, or TOML; - for stdin)")
 	syncCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "Show what would change without applying")
 	syncCmd.Flags().BoolVar(&syncForce, "force", false, "Update existing labels that differ")
 	syncCmd.Flags().BoolVar(&syncDeleteUnmanaged, "delete-unmanaged", false, "Delete labels not in file")
 	syncCmd.Flags().BoolVarP(&syncYes, "yes", "y", false, "Skip confirmation prompt")
+	syncCmd.Flags().BoolVarP(&syncInteractive, "interactive", "i", false, "Choose which changes to apply")
+	syncCmd.Flags().BoolVarP(&syncVerbose, "verbose", "v", false, "Show matching labels and every field of changed labels")
+	syncCmd.Flags().StringVar(&syncOutput, "output", outputText, "Output format (text or markdown)")
+	syncCmd.Flags().StringVar(&syncInputFormat, "input-format", "", "Label file format (default: detected)")
+	syncCmd.Flags().StringArrayVar(&syncVars, "var", nil, "Set a template variable (NAME=VALUE, repeatable)")
+	syncCmd.Flags().BoolVar(&syncAcceptRenames, "accept-renames", false, "Rename labels that look like renames instead of creating new ones")
+	syncCmd.Flags().StringVar(&syncSort, "sort", string(diff.OrderFile), "Order of changes (file, name, or type)")
+	syncCmd.Flags().BoolVar(&syncGroup, "group", false, "Group changes by label prefix")
 	syncCmd.MarkFlagRequired("file")
 }
 
 func runSync(cmd *cobra.Command, args []string) error {
-	// Parse label file
-	desiredLabels, err := parser.ParseFile(syncFile)
+	report, err := newReporter(syncOutput, syncForce, syncDeleteUnmanaged)
 	if err != nil {
 		return err
 	}
+	if err := report.setOrder(syncSort, syncGroup); err != nil {
+		return err
+	}
 
-	if len(desiredLabels) == 0 {
-		return fmt.Errorf("no labels found in file")
+	if report.markdown && !syncDryRun && !syncYes {
+		return fmt.Errorf("--output markdown requires --dry-run or --yes")
 	}
 
-	// Create API client
-	client, err := api.NewClient(repoFlag)
+	vars, err := parser.ParseVars(syncVars)
 	if err != nil {
 		return err
 	}
 
-	// Get current labels
-	currentLabels, err := client.ListLabels()
+	// Read the file once, as stdin can only be read once. It is parsed for
+	// each repository, since templates and conditions depend on it.
+	data, err := parser.ReadFile(syncFile)
 	if err != nil {
 		return err
 	}
 
-	// Compute diff
-	diffs := diff.ComputeDiff(desiredLabels, currentLabels)
+	// A single --repo wins over the repos of a config profile
+	repos := []string{repoFlag}
+	if repoFlag == "" && len(settings.Repos) > 0 {
+		repos = settings.Repos
+	}
+	if len(repos) == 1 {
+		return syncRepo(report, repos[0], data, vars)
+	}
 
-	// Display diff
-	fmt.Print(format.FormatDiff(diffs, false))
-	fmt.Print(format.FormatSummary(diffs, syncForce, syncDeleteUnmanaged))
+	failed := 0
+	for i, repo := range repos {
+		if err := report.printRepo(repo, i == 0); err != nil {
+			return err
+		}
+		if err := syncRepo(report, repo, data, vars); err != nil {
+			fmt.Fprintf(os.Stderr, "✗ %s: %v\n", repo, err)
+			failed++
+		}
+	}
 
-	// Check if there are any changes to apply
-	_, creates, updates, extras := diff.Summary(diffs)
-	totalChanges := creates
-	if syncForce {
-		totalChanges += updates
+	if failed > 0 {
+		return fmt.Errorf("%d of %d repositories failed", failed, len(repos))
+	}
+	return nil
+}
+
+// syncRepo syncs the label file, read as data, to one repository
+func syncRepo(report *reporter, repo string, data []byte, vars parser.Vars) error {
+	client, diffs, err := planSync(syncFile, data, syncInputFormat, repo, vars)
+	if err != nil {
+		return err
 	}
-	if syncDeleteUnmanaged {
-		totalChanges += extras
+
+	// Offer likely renames, unless nobody is there to answer
+	suggestions := diff.SuggestRenames(diffs)
+	if len(suggestions) > 0 && (syncAcceptRenames || !(syncDryRun || syncYes || report.markdown)) {
+		diffs, suggestions, err = acceptRenames(diffs, suggestions, syncAcceptRenames)
+		if err != nil {
+			return err
+		}
 	}
 
-	if totalChanges == 0 {
-		fmt.Println("\n✓ All labels are in sync")
+	// Display diff
+	if err := report.printDiff(diffs, syncVerbose); err != nil {
+		return err
+	}
+	if len(suggestions) > 0 {
+		style := format.DetectStyle()
+		hint := "list the old name in aliases, or use --accept-renames"
+		if syncAcceptRenames {
+			hint = "similar names only; list the old name in aliases to rename"
+		}
+		report.printf("\nPossible renames (%s):\n", hint)
+		for _, s := range suggestions {
+			report.printf("  %s\n", format.FormatSuggestion(s, style))
+		}
+	}
+
+	// Check if there are any changes to apply
+	pending := diff.Pending(diffs, syncForce, syncDeleteUnmanaged)
+	counts := diff.Summary(diffs)
+
+	if len(pending) == 0 && !(syncInteractive && counts.Changes() > 0) {
+		report.printf("\n✓ All labels are in sync\n")
 		return nil
 	}
 
 	if syncDryRun {
-		fmt.Println("\n(dry-run mode: no changes applied)")
+		report.printf("\n(dry-run mode: no changes applied)\n")
 		return nil
 	}
 
-	// Prompt for confirmation
-	if !syncYes {
-		fmt.Printf("\n? Apply changes? (y/N) ")
-		reader := bufio.NewReader(os.Stdin)
-		response, err := reader.ReadString('\n')
+	// Let the user pick changes, or confirm the whole set
+	if syncInteractive {
+		pending, err = prompt.SelectDiffs(diffs, pending)
+		if errors.Is(err, prompt.ErrCancelled) {
+			fmt.Println("Cancelled.")
+			return nil
+		}
 		if err != nil {
 			return err
 		}
-
-		response = strings.TrimSpace(strings.ToLower(response))
-		if response != "y" && response != "yes" {
+		if len(pending) == 0 {
+			fmt.Println("No changes selected.")
+			return nil
+		}
+	} else if !syncYes {
+		fmt.Println()
+		ok, err := prompt.Confirm("? Apply changes?")
+		if err != nil {
+			return err
+		}
+		if !ok {
 			fmt.Println("Cancelled.")
 			return nil
 		}
 	}
 
 	// Apply changes
-	created, updated, deleted := 0, 0, 0
-
-	for _, d := range diffs {
-		switch d.Type {
-		case diff.DiffTypeCreate:
-			input := api.LabelInput{
-				Name:        d.Desired.Name,
-				Color:       d.Desired.Color,
-				Description: d.Desired.Description,
-			}
-			_, err := client.CreateLabel(input)
+	result := applyDiffs(client, pending, report.out())
+
+	return report.printResult(result)
+}
+
+// acceptRenames turns the suggested renames the user confirms into renames.
+// If all is set, suggestions that rest on more than the name are accepted
+// without asking, and the rest are returned to be listed.
+func acceptRenames(diffs []diff.LabelDiff, suggestions []diff.RenameSuggestion, all bool) ([]diff.LabelDiff, []diff.RenameSuggestion, error) {
+	if !all {
+		fmt.Println("Possible renames:")
+	}
+	var unanswered []diff.RenameSuggestion
+	for _, s := range suggestions {
+		if all && !s.Confident() {
+			unanswered = append(unanswered, s)
+			continue
+		}
+		if !all {
+			ok, err := prompt.Confirm(fmt.Sprintf("? Rename %s → %s instead of creating it? (%s)", s.From, s.To, s.Reason()))
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "  ✗ Failed to create %s: %v\n", d.Name, err)
-			} else {
-				fmt.Printf("  ✓ Created %s\n", d.Name)
-				created++
+				return nil, nil, err
 			}
-
-		case diff.DiffTypeUpdate:
-			if syncForce {
-				input := api.LabelInput{
-					Name:        d.Desired.Name,
-					Color:       d.Desired.Color,
-					Description: d.Desired.Description,
-				}
-				_, err := client.UpdateLabel(d.Name, input)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "  ✗ Failed to update %s: %v\n", d.Name, err)
-				} else {
-					fmt.Printf("  ✓ Updated %s\n", d.Name)
-					updated++
-				}
+			if !ok {
+				continue
 			}
+		}
+		diffs = diff.ApplyRename(diffs, s)
+	}
+	if !all {
+		fmt.Println()
+	}
+	return diffs, unanswered, nil
+}
 
-		case diff.DiffTypeExtra:
-			if syncDeleteUnmanaged {
-				err := client.DeleteLabel(d.Name)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "  ✗ Failed to delete %s: %v\n", d.Name, err)
-				} else {
-					fmt.Printf("  ✓ Deleted %s\n", d.Name)
-					deleted++
-				}
-			}
+// planSync parses the label file, read as data, expanding its templates for
+// the repository, and diffs it against the repository's labels. An empty
+// inputFormat detects the file's format.
+func planSync(file string, data []byte, inputFormat, repo string, vars parser.Vars) (api.Store, []diff.LabelDiff, error) {
+	var format parser.Format
+	if inputFormat != "" {
+		f, err := parser.ParseFormat(inputFormat)
+		if err != nil {
+			return nil, nil, err
 		}
+		format = f
 	}
 
-	fmt.Println()
-	fmt.Println(format.FormatResult(created, updated, deleted))
+	// Create API client
+	client, err := newStore(repo, api.Options{Hostname: hostnameFlag})
+	if err != nil {
+		return nil, nil, err
+	}
 
-	return nil
+	// Parse label file, looking up the repository only if it is referenced
+	labelFile, err := parser.ParseLabelData(file, data, format, &parser.Template{
+		Vars: vars,
+		Repo: func() (*api.Repository, error) { return api.Describe(client) },
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	desiredLabels := labelFile.Labels
+
+	if len(desiredLabels) == 0 && len(labelFile.Excluded) == 0 {
+		return nil, nil, fmt.Errorf("no labels found in file")
+	}
+
+	// Get current labels
+	currentLabels, err := client.ListLabels()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Only labels within the config's filters are managed
+	desiredLabels = filterLabels(desiredLabels)
+	currentLabels = filterLabels(currentLabels)
+
+	// Policies also cover repository labels that are not in the file
+	api.ApplyPolicies(currentLabels, labelFile.Policies)
+
+	// Ignore fields the provider cannot store, so they never show as changes
+	api.StripUnsupported(client, desiredLabels)
+
+	// Compute diff. Labels the file defines for other repositories are not
+	// unmanaged here.
+	diffs := diff.ComputeDiff(desiredLabels, currentLabels)
+	diff.MarkExcluded(diffs, labelFile.Excluded)
+	return client, diffs, nil
+}
+
+// filterLabels drops labels outside the include and exclude globs of the
+// config settings
+func filterLabels(labels []api.Label) []api.Label {
+	if len(settings.Include) == 0 && len(settings.Exclude) == 0 {
+		return labels
+	}
+
+	filtered := labels[:0:0]
+	for _, l := range labels {
+		if settings.Manages(l.Name) {
+			filtered = append(filtered, l)
+		}
+	}
+	return filtered
 }
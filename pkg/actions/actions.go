@@ -0,0 +1,109 @@
+package actions
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Annotation is the location a workflow error or warning refers to
+type Annotation struct {
+	File   string
+	Line   int
+	Column int
+	Title  string
+}
+
+// IsActions reports whether the process is running in GitHub Actions
+func IsActions() bool {
+	return os.Getenv("GITHUB_ACTIONS") == "true"
+}
+
+// Input returns the value of an action input. The runner exposes inputs as
+// INPUT_<NAME> with the name upper-cased; hyphens are kept by the runner but
+// the underscore form is accepted too, since composite actions set it by hand.
+func Input(name string) string {
+	key := "INPUT_" + strings.ToUpper(strings.ReplaceAll(name, " ", "_"))
+	if v, ok := os.LookupEnv(key); ok {
+		return strings.TrimSpace(v)
+	}
+	return strings.TrimSpace(os.Getenv(strings.ReplaceAll(key, "-", "_")))
+}
+
+// BoolInput returns an action input parsed as a boolean, or def if unset
+func BoolInput(name string, def bool) (bool, error) {
+	v := Input(name)
+	if v == "" {
+		return def, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("input %s: %q is not a boolean", name, v)
+	}
+	return b, nil
+}
+
+// Error writes an ::error:: workflow command
+func Error(w io.Writer, a Annotation, message string) {
+	command(w, "error", a, message)
+}
+
+// Warning writes a ::warning:: workflow command
+func Warning(w io.Writer, a Annotation, message string) {
+	command(w, "warning", a, message)
+}
+
+// SetOutput records a step output in the $GITHUB_OUTPUT file
+func SetOutput(name, value string) error {
+	path := os.Getenv("GITHUB_OUTPUT")
+	if path == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open step outputs: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := fmt.Fprintf(f, "%s=%s\n", name, value); err != nil {
+		return fmt.Errorf("failed to write step output: %w", err)
+	}
+
+	return nil
+}
+
+func command(w io.Writer, name string, a Annotation, message string) {
+	var props []string
+	if a.File != "" {
+		props = append(props, "file="+escapeProperty(a.File))
+	}
+	if a.Line > 0 {
+		props = append(props, fmt.Sprintf("line=%d", a.Line))
+	}
+	if a.Column > 0 {
+		props = append(props, fmt.Sprintf("col=%d", a.Column))
+	}
+	if a.Title != "" {
+		props = append(props, "title="+escapeProperty(a.Title))
+	}
+
+	if len(props) > 0 {
+		name += " " + strings.Join(props, ",")
+	}
+	fmt.Fprintf(w, "::%s::%s\n", name, escapeData(message))
+}
+
+func escapeData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	return strings.ReplaceAll(s, "\n", "%0A")
+}
+
+func escapeProperty(s string) string {
+	s = escapeData(s)
+	s = strings.ReplaceAll(s, ":", "%3A")
+	return strings.ReplaceAll(s, ",", "%2C")
+}
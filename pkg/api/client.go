@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/cli/go-gh/v2/pkg/auth"
 	"github.com/cli/go-gh/v2/pkg/repository"
 )
 
@@ -16,41 +19,128 @@ type Client struct {
 }
 
 type Label struct {
-	Name        string `json:"name" yaml:"name"`
-	Color       string `json:"color" yaml:"color"`
-	Description string `json:"description" yaml:"description"`
+	Name        string `json:"name" yaml:"name" toml:"name"`
+	Color       string `json:"color" yaml:"color" toml:"color"`
+	Description string `json:"description" yaml:"description" toml:"description"`
+
+	// Priority orders labels on providers that support it (GitLab). Nil
+	// means unset, and unset priorities are not compared.
+	Priority *int `json:"priority,omitempty" yaml:"priority,omitempty" toml:"priority,omitempty"`
+
+	// Exclusive marks a scoped label as mutually exclusive with others in
+	// its scope on providers that support it (Gitea, Forgejo)
+	Exclusive *bool `json:"exclusive,omitempty" yaml:"exclusive,omitempty" toml:"exclusive,omitempty"`
+
+	// Aliases are earlier names of the label. A repository label with one of
+	// these names is renamed instead of a new label being created.
+	Aliases []string `json:"aliases,omitempty" yaml:"aliases,omitempty" toml:"aliases,omitempty"`
+
+	// Delete marks a label that should be removed from the repository
+	Delete bool `json:"delete,omitempty" yaml:"delete,omitempty" toml:"delete,omitempty"`
+
+	// Policy overrides how sync treats the label (create-only, enforce, or
+	// ignore); empty follows the command-line flags
+	Policy Policy `json:"policy,omitempty" yaml:"policy,omitempty" toml:"policy,omitempty"`
+
+	// Protected labels are never deleted
+	Protected bool `json:"protected,omitempty" yaml:"protected,omitempty" toml:"protected,omitempty"`
+
+	// When limits the label to repositories matching the condition
+	When *Condition `json:"when,omitempty" yaml:"when,omitempty" toml:"when,omitempty"`
+
+	// IssueCount is the number of issues using the label, not counting pull
+	// requests. It is only populated by backends that can fetch it cheaply.
+	IssueCount int `json:"-" yaml:"-" toml:"-"`
 }
 
 type LabelInput struct {
 	Name        string `json:"name"`
 	Color       string `json:"color"`
 	Description string `json:"description,omitempty"`
+
+	// Priority and Exclusive are not part of the GitHub API; providers that
+	// support them send them themselves
+	Priority  *int  `json:"-"`
+	Exclusive *bool `json:"-"`
+}
+
+// Options configures how a client connects to the API
+type Options struct {
+	// Hostname is the host used for repositories given as OWNER/REPO. When
+	// empty, the default gh host is used.
+	Hostname string
+
+	// AuthToken overrides the token gh has stored for the host
+	AuthToken string
+
+	// Transport overrides the HTTP transport, e.g. to talk to a fake server
+	Transport http.RoundTripper
+}
+
+// clientOptions returns go-gh client options for host, with the token gh
+// has stored for that host unless one was given
+func (o Options) clientOptions(host string) (api.ClientOptions, error) {
+	token := o.AuthToken
+	if token == "" {
+		token, _ = auth.TokenForHost(host)
+	}
+	if token == "" {
+		return api.ClientOptions{}, fmt.Errorf("no authentication token found for %s (run gh auth login --hostname %s)", host, host)
+	}
+
+	return api.ClientOptions{
+		Host:      host,
+		AuthToken: token,
+		Transport: o.Transport,
+	}, nil
 }
 
 // NewClient creates a new API client
-func NewClient(repoOverride string) (*Client, error) {
-	restClient, err := api.DefaultRESTClient()
+func NewClient(repoOverride string, opts Options) (*Client, error) {
+	repo, err := resolveRepository(repoOverride, opts.Hostname)
+	if err != nil {
+		return nil, err
+	}
+
+	clientOpts, err := opts.clientOptions(repo.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	restClient, err := api.NewRESTClient(clientOpts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create REST client: %w", err)
 	}
 
-	var repo repository.Repository
+	return &Client{
+		restClient: restClient,
+		repo:       repo,
+	}, nil
+}
+
+// resolveRepository parses the --repo value, which may be OWNER/REPO,
+// HOST/OWNER/REPO, or a URL, falling back to the repository of the current
+// directory. Repositories without a host use hostname if it is set.
+func resolveRepository(repoOverride, hostname string) (repository.Repository, error) {
 	if repoOverride != "" {
-		repo, err = repository.Parse(repoOverride)
-		if err != nil {
-			return nil, fmt.Errorf("invalid repository format: %w", err)
+		var repo repository.Repository
+		var err error
+		if hostname != "" {
+			repo, err = repository.ParseWithHost(repoOverride, hostname)
+		} else {
+			repo, err = repository.Parse(repoOverride)
 		}
-	} else {
-		repo, err = repository.Current()
 		if err != nil {
-			return nil, fmt.Errorf("could not determine repository (use --repo flag): %w", err)
+			return repository.Repository{}, fmt.Errorf("invalid repository format: %w", err)
 		}
+		return repo, nil
 	}
 
-	return &Client{
-		restClient: restClient,
-		repo:       repo,
-	}, nil
+	repo, err := repository.Current()
+	if err != nil {
+		return repository.Repository{}, fmt.Errorf("could not determine repository (use --repo flag): %w", err)
+	}
+	return repo, nil
 }
 
 // ListLabels lists all labels in the repository
@@ -104,7 +194,16 @@ func (c *Client) UpdateLabel(name string, input LabelInput) (*Label, error) {
 	encodedName := url.PathEscape(name)
 	path := fmt.Sprintf("repos/%s/%s/labels/%s", c.repo.Owner, c.repo.Name, encodedName)
 
-	body, err := json.Marshal(input)
+	// The REST API renames labels through new_name
+	update := struct {
+		LabelInput
+		NewName string `json:"new_name,omitempty"`
+	}{LabelInput: input}
+	if input.Name != name {
+		update.NewName = input.Name
+	}
+
+	body, err := json.Marshal(update)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal input: %w", err)
 	}
@@ -132,9 +231,15 @@ func (c *Client) DeleteLabel(name string) error {
 
 // NormalizeColor normalizes color values (strips #, converts to lowercase)
 func NormalizeColor(color string) string {
-	// Remove # prefix if present
-	if len(color) > 0 && color[0] == '#' {
-		color = color[1:]
+	return strings.ToLower(strings.TrimPrefix(color, "#"))
+}
+
+// SplitScope splits a label name into its lowercased scope, the part before
+// its first ":" or "/", and the rest, so "Priority: high" gives "priority"
+// and "high". Names without a scope give an empty scope and the whole name.
+func SplitScope(name string) (string, string) {
+	if i := strings.IndexAny(name, ":/"); i > 0 {
+		return strings.ToLower(strings.TrimSpace(name[:i])), strings.TrimSpace(name[i+1:])
 	}
-	return color
+	return "", name
 }
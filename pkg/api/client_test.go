@@ -0,0 +1,327 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeGitHub stands in for the GitHub REST and GraphQL APIs. Requests reach
+// it through its transport whatever their host, and the URL each request was
+// sent to is recorded so tests can check routing.
+type fakeGitHub struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu       sync.Mutex
+	requests []string
+
+	// handle serves a request whose path has the API prefix removed
+	handle func(w http.ResponseWriter, r *http.Request, body []byte)
+}
+
+func newFakeGitHub(t *testing.T, handle func(w http.ResponseWriter, r *http.Request, body []byte)) *fakeGitHub {
+	f := &fakeGitHub{t: t, handle: handle}
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "token test-token" {
+			t.Errorf("Authorization = %q, want the test token", got)
+		}
+		body, _ := io.ReadAll(r.Body)
+
+		// Enterprise hosts serve the same API under a prefix
+		r.URL.Path = strings.TrimPrefix(r.URL.Path, "/api/v3")
+		r.URL.Path = strings.TrimPrefix(r.URL.Path, "/api")
+		f.handle(w, r, body)
+	}))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// options returns client options that send every request to the fake
+func (f *fakeGitHub) options(hostname string) Options {
+	return Options{Hostname: hostname, AuthToken: "test-token", Transport: f}
+}
+
+// RoundTrip records the request's URL and sends it to the fake server
+func (f *fakeGitHub) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.mu.Lock()
+	f.requests = append(f.requests, req.Method+" "+req.URL.String())
+	f.mu.Unlock()
+
+	target, _ := url.Parse(f.server.URL)
+	req = req.Clone(req.Context())
+	req.URL.Scheme = target.Scheme
+	req.URL.Host = target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func (f *fakeGitHub) lastRequest() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.requests) == 0 {
+		return ""
+	}
+	return f.requests[len(f.requests)-1]
+}
+
+func writeJSON(t *testing.T, w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClientListLabels(t *testing.T) {
+	fake := newFakeGitHub(t, func(w http.ResponseWriter, r *http.Request, body []byte) {
+		if r.Method != http.MethodGet || r.URL.Path != "/repos/octo/hello/labels" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		writeJSON(t, w, http.StatusOK, []map[string]string{
+			{"name": "bug", "color": "d73a4a", "description": "Something isn't working"},
+			{"name": "type: feature", "color": "a2eeef", "description": ""},
+		})
+	})
+
+	client, err := NewClient("octo/hello", fake.options(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	labels, err := client.ListLabels()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(labels) != 2 || labels[0].Name != "bug" || labels[0].Color != "d73a4a" || labels[1].Name != "type: feature" {
+		t.Errorf("labels = %+v", labels)
+	}
+	if got, want := fake.lastRequest(), "GET https://api.github.com/repos/octo/hello/labels?per_page=100"; got != want {
+		t.Errorf("request = %q, want %q", got, want)
+	}
+}
+
+func TestClientChanges(t *testing.T) {
+	var got []string
+	fake := newFakeGitHub(t, func(w http.ResponseWriter, r *http.Request, body []byte) {
+		got = append(got, r.Method+" "+r.URL.EscapedPath()+" "+strings.TrimSpace(string(body)))
+		switch r.Method {
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodPost:
+			writeJSON(t, w, http.StatusCreated, map[string]string{"name": "bug", "color": "d73a4a"})
+		default:
+			writeJSON(t, w, http.StatusOK, map[string]string{"name": "type: bug", "color": "d73a4a"})
+		}
+	})
+
+	client, err := NewClient("octo/hello", fake.options(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.CreateLabel(LabelInput{Name: "bug", Color: "d73a4a"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.UpdateLabel("bug", LabelInput{Name: "type: bug", Color: "d73a4a", Description: "Broken"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.DeleteLabel("type: bug"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		`POST /repos/octo/hello/labels {"name":"bug","color":"d73a4a"}`,
+		`PATCH /repos/octo/hello/labels/bug {"name":"type: bug","color":"d73a4a","description":"Broken","new_name":"type: bug"}`,
+		`DELETE /repos/octo/hello/labels/type:%20bug `,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("requests:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestClientError(t *testing.T) {
+	fake := newFakeGitHub(t, func(w http.ResponseWriter, r *http.Request, body []byte) {
+		writeJSON(t, w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+	})
+
+	client, err := NewClient("octo/hello", fake.options(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = client.DeleteLabel("missing")
+	if err == nil || !strings.Contains(err.Error(), "failed to delete label") || !strings.Contains(err.Error(), "404") {
+		t.Errorf("err = %v, want a failed delete with HTTP 404", err)
+	}
+}
+
+func TestHostnameRouting(t *testing.T) {
+	tests := []struct {
+		name     string
+		repo     string
+		hostname string
+		rest     string
+		graphql  string
+	}{
+		{"default host", "octo/hello", "", "https://api.github.com/repos/octo/hello/labels?per_page=100", "https://api.github.com/graphql"},
+		{"hostname", "octo/hello", "ghe.example.com", "https://ghe.example.com/api/v3/repos/octo/hello/labels?per_page=100", "https://ghe.example.com/api/graphql"},
+		{"host in repo", "ghe.example.com/octo/hello", "", "https://ghe.example.com/api/v3/repos/octo/hello/labels?per_page=100", "https://ghe.example.com/api/graphql"},
+		{"host in repo wins", "github.com/octo/hello", "ghe.example.com", "https://api.github.com/repos/octo/hello/labels?per_page=100", "https://api.github.com/graphql"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeGitHub(t, func(w http.ResponseWriter, r *http.Request, body []byte) {
+				if r.URL.Path == "/graphql" {
+					writeJSON(t, w, http.StatusOK, map[string]any{"data": map[string]any{
+						"repository": map[string]any{"id": "R_1", "labels": map[string]any{"nodes": []any{}}},
+					}})
+					return
+				}
+				writeJSON(t, w, http.StatusOK, []any{})
+			})
+
+			rest, err := NewClient(tt.repo, fake.options(tt.hostname))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := rest.ListLabels(); err != nil {
+				t.Fatal(err)
+			}
+			if got := fake.lastRequest(); got != "GET "+tt.rest {
+				t.Errorf("REST request = %q, want GET %s", got, tt.rest)
+			}
+
+			gql, err := NewGraphQLClient(tt.repo, fake.options(tt.hostname))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := gql.ListLabels(); err != nil {
+				t.Fatal(err)
+			}
+			if got := fake.lastRequest(); got != "POST "+tt.graphql {
+				t.Errorf("GraphQL request = %q, want POST %s", got, tt.graphql)
+			}
+		})
+	}
+}
+
+// graphQLRequest is the body of a GraphQL request
+type graphQLRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables"`
+}
+
+func TestGraphQLListLabelsPaginates(t *testing.T) {
+	pages := map[string]map[string]any{
+		"": {
+			"nodes":    []any{map[string]any{"id": "L_1", "name": "bug", "color": "d73a4a", "issues": map[string]any{"totalCount": 3}}},
+			"pageInfo": map[string]any{"hasNextPage": true, "endCursor": "c1"},
+		},
+		"c1": {
+			"nodes":    []any{map[string]any{"id": "L_2", "name": "docs", "color": "0075ca", "issues": map[string]any{"totalCount": 0}}},
+			"pageInfo": map[string]any{"hasNextPage": false},
+		},
+	}
+
+	fake := newFakeGitHub(t, func(w http.ResponseWriter, r *http.Request, body []byte) {
+		var req graphQLRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Fatal(err)
+		}
+		after, _ := req.Variables["after"].(string)
+		writeJSON(t, w, http.StatusOK, map[string]any{"data": map[string]any{
+			"repository": map[string]any{"id": "R_1", "labels": pages[after]},
+		}})
+	})
+
+	client, err := NewGraphQLClient("octo/hello", fake.options(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	labels, err := client.ListLabels()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(labels) != 2 || labels[0].Name != "bug" || labels[0].IssueCount != 3 || labels[1].Name != "docs" {
+		t.Errorf("labels = %+v", labels)
+	}
+}
+
+func TestGraphQLApplyBatch(t *testing.T) {
+	var mutation graphQLRequest
+	fake := newFakeGitHub(t, func(w http.ResponseWriter, r *http.Request, body []byte) {
+		var req graphQLRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Fatal(err)
+		}
+		if strings.HasPrefix(req.Query, "query") {
+			writeJSON(t, w, http.StatusOK, map[string]any{"data": map[string]any{
+				"repository": map[string]any{"id": "R_1", "labels": map[string]any{
+					"nodes": []any{
+						map[string]any{"id": "L_1", "name": "bug", "color": "d73a4a"},
+						map[string]any{"id": "L_2", "name": "old", "color": "ffffff"},
+					},
+				}},
+			}})
+			return
+		}
+
+		mutation = req
+		writeJSON(t, w, http.StatusOK, map[string]any{
+			"data": map[string]any{
+				"op0": map[string]any{"label": map[string]any{"id": "L_3", "name": "docs", "color": "0075ca"}},
+				"op1": nil,
+				"op2": map[string]any{"clientMutationId": nil},
+			},
+			"errors": []any{map[string]any{"message": "Name has already been taken", "path": []any{"op1"}}},
+		})
+	})
+
+	client, err := NewGraphQLClient("octo/hello", fake.options(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs := client.ApplyBatch([]Operation{
+		{Kind: OperationCreate, Name: "docs", Input: LabelInput{Name: "docs", Color: "0075ca"}},
+		{Kind: OperationUpdate, Name: "bug", Input: LabelInput{Name: "old", Color: "d73a4a"}},
+		{Kind: OperationDelete, Name: "old"},
+		{Kind: OperationDelete, Name: "missing"},
+	})
+
+	if errs[0] != nil || errs[2] != nil {
+		t.Errorf("errs = %v, want the create and delete to succeed", errs)
+	}
+	if errs[1] == nil || !strings.Contains(errs[1].Error(), "Name has already been taken") {
+		t.Errorf("update err = %v, want the error for its alias", errs[1])
+	}
+	if errs[3] == nil || !strings.Contains(errs[3].Error(), "not found") {
+		t.Errorf("delete err = %v, want not found", errs[3])
+	}
+
+	// The unknown label is left out of the request
+	for _, field := range []string{"op0: createLabel", "op1: updateLabel", "op2: deleteLabel"} {
+		if !strings.Contains(mutation.Query, field) {
+			t.Errorf("mutation is missing %q:\n%s", field, mutation.Query)
+		}
+	}
+	if strings.Contains(mutation.Query, "op3") {
+		t.Errorf("mutation includes the unknown label:\n%s", mutation.Query)
+	}
+	if id := mutation.Variables["input1"].(map[string]any)["id"]; id != "L_1" {
+		t.Errorf("update id = %v, want L_1", id)
+	}
+}
+
+func TestNormalizeColor(t *testing.T) {
+	for color, want := range map[string]string{"#D73A4A": "d73a4a", "d73a4a": "d73a4a", "A2EEEF": "a2eeef", "": ""} {
+		if got := NormalizeColor(color); got != want {
+			t.Errorf("NormalizeColor(%q) = %q, want %q", color, got, want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Visibilities a condition can match
+var visibilities = []string{"public", "private", "internal"}
+
+// Condition limits labels to repositories with matching attributes. Every
+// field that is set must match, and a field matches if any of its entries
+// does.
+type Condition struct {
+	// Topics match if the repository has one of the topics
+	Topics []string `json:"topics,omitempty" yaml:"topics,omitempty" toml:"topics,omitempty"`
+
+	// Language matches the repository's primary language, ignoring case
+	Language []string `json:"language,omitempty" yaml:"language,omitempty" toml:"language,omitempty"`
+
+	// Visibility is public, private, or internal
+	Visibility []string `json:"visibility,omitempty" yaml:"visibility,omitempty" toml:"visibility,omitempty"`
+
+	// Name globs match the repository name or OWNER/NAME
+	Name []string `json:"name,omitempty" yaml:"name,omitempty" toml:"name,omitempty"`
+
+	// All are further conditions that must also match, e.g. that of the
+	// group a label belongs to. They are written out with the label, so a
+	// grouped label keeps its group's condition when converted.
+	All []*Condition `json:"all,omitempty" yaml:"all,omitempty" toml:"all,omitempty"`
+}
+
+// Validate checks the condition's visibilities and name globs
+func (c *Condition) Validate() error {
+	if c == nil {
+		return nil
+	}
+	for _, v := range c.Visibility {
+		if !slices.Contains(visibilities, strings.ToLower(v)) {
+			return fmt.Errorf("invalid visibility %q (use %s)", v, strings.Join(visibilities, ", "))
+		}
+	}
+	for _, pattern := range c.Name {
+		if err := ValidateGlob(pattern); err != nil {
+			return fmt.Errorf("invalid name pattern %q", pattern)
+		}
+	}
+	for _, sub := range c.All {
+		if err := sub.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// And returns a condition that matches when both c and other do. Either may
+// be nil.
+func (c *Condition) And(other *Condition) *Condition {
+	if c == nil {
+		return other
+	}
+	if other == nil {
+		return c
+	}
+	combined := *c
+	combined.All = append(slices.Clip(c.All), other)
+	return &combined
+}
+
+// Excludes reports whether no repository can match both c and other, because
+// together they allow no language or no visibility. Topics and name globs are
+// assumed to overlap.
+func (c *Condition) Excludes(other *Condition) bool {
+	combined := c.And(other)
+	for _, field := range []func(*Condition) []string{
+		func(c *Condition) []string { return c.Language },
+		func(c *Condition) []string { return c.Visibility },
+	} {
+		if allowed, constrained := combined.allowed(field); constrained && len(allowed) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// allowed returns the lowercased values of a single-valued repository
+// attribute that satisfy every part of the condition, and whether any part
+// constrains it
+func (c *Condition) allowed(field func(*Condition) []string) ([]string, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	var allowed []string
+	constrained := false
+	intersect := func(values []string) {
+		if !constrained {
+			allowed, constrained = values, true
+			return
+		}
+		allowed = slices.DeleteFunc(allowed, func(v string) bool { return !slices.Contains(values, v) })
+	}
+
+	if values := field(c); len(values) > 0 {
+		lower := make([]string, len(values))
+		for i, v := range values {
+			lower[i] = strings.ToLower(v)
+		}
+		intersect(lower)
+	}
+	for _, sub := range c.All {
+		if values, ok := sub.allowed(field); ok {
+			intersect(values)
+		}
+	}
+	return allowed, constrained
+}
+
+// Matches reports whether the repository satisfies the condition. A nil
+// condition matches every repository.
+func (c *Condition) Matches(repo *Repository) bool {
+	if c == nil {
+		return true
+	}
+
+	if len(c.Topics) > 0 && !slices.ContainsFunc(c.Topics, func(t string) bool {
+		return slices.ContainsFunc(repo.Topics, func(rt string) bool { return strings.EqualFold(t, rt) })
+	}) {
+		return false
+	}
+	if len(c.Language) > 0 && !containsFold(c.Language, repo.Language) {
+		return false
+	}
+	if len(c.Visibility) > 0 && !containsFold(c.Visibility, repo.Visibility) {
+		return false
+	}
+	if len(c.Name) > 0 && !slices.ContainsFunc(c.Name, func(pattern string) bool {
+		return MatchGlob(pattern, repo.Name) || MatchGlob(pattern, repo.Owner+"/"+repo.Name)
+	}) {
+		return false
+	}
+
+	for _, sub := range c.All {
+		if !sub.Matches(repo) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsFold(values []string, s string) bool {
+	return s != "" && slices.ContainsFunc(values, func(v string) bool { return strings.EqualFold(v, s) })
+}
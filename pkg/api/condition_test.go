@@ -0,0 +1,52 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestConditionAllRoundTrip(t *testing.T) {
+	group := &Condition{Language: []string{"Go"}}
+	when := (&Condition{Visibility: []string{"public"}}).And(group)
+
+	data, err := json.Marshal(when)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded *Condition
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	goRepo := &Repository{Language: "Go", Visibility: "public"}
+	pyRepo := &Repository{Language: "Python", Visibility: "public"}
+	if !decoded.Matches(goRepo) || decoded.Matches(pyRepo) {
+		t.Errorf("decoded condition %s lost the group's language", data)
+	}
+}
+
+func TestConditionExcludes(t *testing.T) {
+	goRepos := &Condition{Language: []string{"Go"}}
+	pyRepos := &Condition{Language: []string{"python"}}
+	public := &Condition{Visibility: []string{"public"}}
+	private := &Condition{Visibility: []string{"private", "internal"}}
+	topic := &Condition{Topics: []string{"cli"}}
+
+	tests := []struct {
+		name string
+		a, b *Condition
+		want bool
+	}{
+		{"different languages", goRepos, pyRepos, true},
+		{"same language", goRepos, &Condition{Language: []string{"GO", "Rust"}}, false},
+		{"different visibility in a group", public.And(goRepos), private, true},
+		{"language and visibility", goRepos, public, false},
+		{"topics may overlap", topic, &Condition{Topics: []string{"web"}}, false},
+		{"no condition", nil, goRepos, false},
+	}
+	for _, tt := range tests {
+		if got := tt.a.Excludes(tt.b); got != tt.want {
+			t.Errorf("%s: Excludes = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
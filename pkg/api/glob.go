@@ -0,0 +1,104 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MatchGlob reports whether name matches a label glob. Unlike filepath.Match,
+// "*" matches any characters including "/", so "area/*" and "*bug*" behave
+// the same for every label and on every OS. "?" matches one character,
+// "[...]" a character class ("[!...]" negated), and "\" escapes the next
+// character. An invalid pattern matches nothing.
+func MatchGlob(pattern, name string) bool {
+	re, err := compileGlob(pattern)
+	return err == nil && re.MatchString(name)
+}
+
+// ValidateGlob checks that a label glob is well formed
+func ValidateGlob(pattern string) error {
+	_, err := compileGlob(pattern)
+	return err
+}
+
+// compileGlob translates a label glob into an anchored regular expression
+func compileGlob(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString(`^(?s:`)
+
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; r {
+		case '*':
+			b.WriteString(`.*`)
+		case '?':
+			b.WriteString(`.`)
+		case '\\':
+			i++
+			if i == len(runes) {
+				return nil, fmt.Errorf("invalid pattern %q: trailing \\", pattern)
+			}
+			b.WriteString(regexp.QuoteMeta(string(runes[i])))
+		case '[':
+			end, class, err := globClass(runes, i)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+			b.WriteString(class)
+			i = end
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	b.WriteString(`)$`)
+	return regexp.Compile(b.String())
+}
+
+// globClass translates the character class starting at runes[start], and
+// returns the index of its closing bracket
+func globClass(runes []rune, start int) (int, string, error) {
+	var b strings.Builder
+	b.WriteByte('[')
+
+	i := start + 1
+	if i < len(runes) && (runes[i] == '!' || runes[i] == '^') {
+		b.WriteByte('^')
+		i++
+	}
+
+	empty := true
+	for ; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case r == ']':
+			if empty {
+				return 0, "", fmt.Errorf("empty character class")
+			}
+			b.WriteByte(']')
+			return i, b.String(), nil
+		case r == '\\':
+			i++
+			if i == len(runes) {
+				return 0, "", fmt.Errorf("trailing \\")
+			}
+			b.WriteString(classLiteral(runes[i]))
+		case r == '-' && !empty && i+1 < len(runes) && runes[i+1] != ']':
+			b.WriteByte('-')
+		default:
+			b.WriteString(classLiteral(r))
+		}
+		empty = false
+	}
+
+	return 0, "", fmt.Errorf("unclosed character class")
+}
+
+// classLiteral writes r for use inside a character class, escaping
+// punctuation so that it is taken literally
+func classLiteral(r rune) string {
+	if r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r > 0x7f {
+		return string(r)
+	}
+	return `\` + string(r)
+}
@@ -0,0 +1,50 @@
+package api
+
+import "testing"
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*", "anything at all", true},
+		{"area/*", "area/api", true},
+		{"area/*", "area/api/v2", true},
+		{"*bug*", "type/bug-report", true},
+		{"priority:*", "priority: high", true},
+		{"priority:*", "Priority: high", false},
+		{"size/?", "size/S", true},
+		{"size/?", "size/XL", false},
+		{"p[0-3]", "p2", true},
+		{"p[!0-3]", "p2", false},
+		{"p[!0-3]", "p4", true},
+		{"[ab-]x", "-x", true},
+		{"[\\d]", "d", true},
+		{"[\\d]", "1", false},
+		{"a.b", "axb", false},
+		{"a\\*", "a*", true},
+		{"a\\*", "ab", false},
+		{"(wip)", "(wip)", true},
+		{"[", "[", false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("MatchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidateGlob(t *testing.T) {
+	for _, pattern := range []string{"*", "area/*", "[a-z]*", "\\[x\\]"} {
+		if err := ValidateGlob(pattern); err != nil {
+			t.Errorf("ValidateGlob(%q) = %v", pattern, err)
+		}
+	}
+	for _, pattern := range []string{"[", "[]", "[a-", "trailing\\", "[\\"} {
+		if err := ValidateGlob(pattern); err == nil {
+			t.Errorf("ValidateGlob(%q) = nil, want an error", pattern)
+		}
+	}
+}
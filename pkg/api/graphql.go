@@ -0,0 +1,277 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/cli/go-gh/v2/pkg/repository"
+)
+
+// batchSize is the number of aliased mutations sent in one GraphQL request
+const batchSize = 50
+
+// GraphQLClient is a label store backed by the GitHub GraphQL API. Labels are
+// fetched with their issue counts in a single paginated query, and changes
+// can be batched into aliased mutations.
+type GraphQLClient struct {
+	gqlClient *api.GraphQLClient
+	repo      repository.Repository
+
+	// repoID and labelIDs cache node IDs needed by mutations
+	repoID   string
+	labelIDs map[string]string
+}
+
+// NewGraphQLClient creates a new GraphQL API client
+func NewGraphQLClient(repoOverride string, opts Options) (*GraphQLClient, error) {
+	repo, err := resolveRepository(repoOverride, opts.Hostname)
+	if err != nil {
+		return nil, err
+	}
+
+	clientOpts, err := opts.clientOptions(repo.Host)
+	if err != nil {
+		return nil, err
+	}
+	// Label mutations were introduced behind this preview
+	clientOpts.Headers = map[string]string{"Accept": "application/vnd.github.bane-preview+json"}
+
+	gqlClient, err := api.NewGraphQLClient(clientOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GraphQL client: %w", err)
+	}
+
+	return &GraphQLClient{
+		gqlClient: gqlClient,
+		repo:      repo,
+		labelIDs:  make(map[string]string),
+	}, nil
+}
+
+type graphQLLabel struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	Description string `json:"description"`
+	Issues      struct {
+		TotalCount int `json:"totalCount"`
+	} `json:"issues"`
+}
+
+func (l graphQLLabel) toLabel() Label {
+	return Label{
+		Name:        l.Name,
+		Color:       l.Color,
+		Description: l.Description,
+		IssueCount:  l.Issues.TotalCount,
+	}
+}
+
+const labelFields = `id name color description issues { totalCount }`
+
+// ListLabels lists all labels in the repository along with their issue counts
+func (c *GraphQLClient) ListLabels() ([]Label, error) {
+	query := `query($owner: String!, $name: String!, $after: String) {
+		repository(owner: $owner, name: $name) {
+			id
+			labels(first: 100, after: $after) {
+				nodes { ` + labelFields + ` }
+				pageInfo { hasNextPage endCursor }
+			}
+		}
+	}`
+
+	var labels []Label
+	variables := map[string]interface{}{
+		"owner": c.repo.Owner,
+		"name":  c.repo.Name,
+		"after": nil,
+	}
+
+	for {
+		var resp struct {
+			Repository struct {
+				ID     string `json:"id"`
+				Labels struct {
+					Nodes    []graphQLLabel `json:"nodes"`
+					PageInfo struct {
+						HasNextPage bool   `json:"hasNextPage"`
+						EndCursor   string `json:"endCursor"`
+					} `json:"pageInfo"`
+				} `json:"labels"`
+			} `json:"repository"`
+		}
+
+		if err := c.gqlClient.Do(query, variables, &resp); err != nil {
+			return nil, fmt.Errorf("failed to list labels: %w", err)
+		}
+
+		c.repoID = resp.Repository.ID
+		for _, node := range resp.Repository.Labels.Nodes {
+			c.labelIDs[node.Name] = node.ID
+			labels = append(labels, node.toLabel())
+		}
+
+		if !resp.Repository.Labels.PageInfo.HasNextPage {
+			break
+		}
+		variables["after"] = resp.Repository.Labels.PageInfo.EndCursor
+	}
+
+	return labels, nil
+}
+
+// CreateLabel creates a new label
+func (c *GraphQLClient) CreateLabel(input LabelInput) (*Label, error) {
+	if errs := c.ApplyBatch([]Operation{{Kind: OperationCreate, Name: input.Name, Input: input}}); errs[0] != nil {
+		return nil, errs[0]
+	}
+	return &Label{Name: input.Name, Color: input.Color, Description: input.Description}, nil
+}
+
+// UpdateLabel updates an existing label
+func (c *GraphQLClient) UpdateLabel(name string, input LabelInput) (*Label, error) {
+	if errs := c.ApplyBatch([]Operation{{Kind: OperationUpdate, Name: name, Input: input}}); errs[0] != nil {
+		return nil, errs[0]
+	}
+	return &Label{Name: input.Name, Color: input.Color, Description: input.Description}, nil
+}
+
+// DeleteLabel deletes a label
+func (c *GraphQLClient) DeleteLabel(name string) error {
+	return c.ApplyBatch([]Operation{{Kind: OperationDelete, Name: name}})[0]
+}
+
+// ApplyBatch applies operations using aliased mutations, batchSize per request
+func (c *GraphQLClient) ApplyBatch(ops []Operation) []error {
+	errs := make([]error, len(ops))
+
+	// Mutations reference node IDs, which ListLabels caches
+	if c.repoID == "" {
+		if _, err := c.ListLabels(); err != nil {
+			for i := range errs {
+				errs[i] = err
+			}
+			return errs
+		}
+	}
+
+	for start := 0; start < len(ops); start += batchSize {
+		end := min(start+batchSize, len(ops))
+		c.applyChunk(ops[start:end], errs[start:end])
+	}
+
+	return errs
+}
+
+// applyChunk sends one request containing a mutation per operation, recording
+// per-operation failures in errs
+func (c *GraphQLClient) applyChunk(ops []Operation, errs []error) {
+	var params, fields []string
+	variables := make(map[string]interface{})
+	aliases := make(map[string]int)
+
+	for i, op := range ops {
+		alias := fmt.Sprintf("op%d", i)
+		variable := fmt.Sprintf("input%d", i)
+
+		var mutation, inputType string
+		var input map[string]interface{}
+
+		switch op.Kind {
+		case OperationCreate:
+			mutation, inputType = "createLabel", "CreateLabelInput!"
+			input = map[string]interface{}{
+				"repositoryId": c.repoID,
+				"name":         op.Input.Name,
+				"color":        op.Input.Color,
+				"description":  op.Input.Description,
+			}
+		case OperationUpdate, OperationDelete:
+			id, ok := c.labelIDs[op.Name]
+			if !ok {
+				errs[i] = fmt.Errorf("failed to %s label: label %q not found", op.Kind, op.Name)
+				continue
+			}
+			if op.Kind == OperationDelete {
+				mutation, inputType = "deleteLabel", "DeleteLabelInput!"
+				input = map[string]interface{}{"id": id}
+			} else {
+				mutation, inputType = "updateLabel", "UpdateLabelInput!"
+				input = map[string]interface{}{
+					"id":          id,
+					"name":        op.Input.Name,
+					"color":       op.Input.Color,
+					"description": op.Input.Description,
+				}
+			}
+		default:
+			errs[i] = fmt.Errorf("unknown operation: %s", op.Kind)
+			continue
+		}
+
+		selection := "clientMutationId"
+		if op.Kind != OperationDelete {
+			selection = "label { " + labelFields + " }"
+		}
+
+		params = append(params, fmt.Sprintf("$%s: %s", variable, inputType))
+		fields = append(fields, fmt.Sprintf("%s: %s(input: $%s) { %s }", alias, mutation, variable, selection))
+		variables[variable] = input
+		aliases[alias] = i
+	}
+
+	if len(fields) == 0 {
+		return
+	}
+
+	query := fmt.Sprintf("mutation(%s) {\n%s\n}", strings.Join(params, ", "), strings.Join(fields, "\n"))
+
+	var resp map[string]*struct {
+		Label *graphQLLabel `json:"label"`
+	}
+	err := c.gqlClient.Do(query, variables, &resp)
+
+	// Attribute errors to the operations whose alias they reference
+	var gqlErr *api.GraphQLError
+	if errors.As(err, &gqlErr) {
+		for _, item := range gqlErr.Errors {
+			if len(item.Path) > 0 {
+				if alias, ok := item.Path[0].(string); ok {
+					if i, ok := aliases[alias]; ok {
+						errs[i] = fmt.Errorf("failed to %s label: %s", ops[i].Kind, item.Message)
+						continue
+					}
+				}
+			}
+			// An error without a path fails the whole request
+			for _, i := range aliases {
+				if errs[i] == nil {
+					errs[i] = fmt.Errorf("failed to %s label: %s", ops[i].Kind, item.Message)
+				}
+			}
+		}
+	} else if err != nil {
+		for _, i := range aliases {
+			errs[i] = fmt.Errorf("failed to %s label: %w", ops[i].Kind, err)
+		}
+	}
+
+	// Keep cached IDs in step with successful changes
+	for alias, i := range aliases {
+		if errs[i] != nil {
+			continue
+		}
+		switch ops[i].Kind {
+		case OperationDelete:
+			delete(c.labelIDs, ops[i].Name)
+		default:
+			if r := resp[alias]; r != nil && r.Label != nil {
+				delete(c.labelIDs, ops[i].Name)
+				c.labelIDs[r.Label.Name] = r.Label.ID
+			}
+		}
+	}
+}
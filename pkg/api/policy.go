@@ -0,0 +1,74 @@
+package api
+
+import (
+	"fmt"
+)
+
+// Policy overrides how sync treats a label
+type Policy string
+
+const (
+	// PolicyDefault follows --force and --delete-unmanaged
+	PolicyDefault Policy = ""
+
+	// PolicyCreateOnly creates the label if it is missing but never changes
+	// it afterwards, even with --force
+	PolicyCreateOnly Policy = "create-only"
+
+	// PolicyEnforce updates the label whenever it differs, even without
+	// --force
+	PolicyEnforce Policy = "enforce"
+
+	// PolicyIgnore leaves the label alone entirely
+	PolicyIgnore Policy = "ignore"
+)
+
+// Valid reports whether p is a known policy
+func (p Policy) Valid() bool {
+	switch p {
+	case PolicyDefault, PolicyCreateOnly, PolicyEnforce, PolicyIgnore:
+		return true
+	}
+	return false
+}
+
+// PolicyRule sets the policy or protection of every label whose name matches
+// a glob, e.g. "priority:*"
+type PolicyRule struct {
+	Match     string `json:"match" yaml:"match" toml:"match"`
+	Policy    Policy `json:"policy,omitempty" yaml:"policy,omitempty" toml:"policy,omitempty"`
+	Protected bool   `json:"protected,omitempty" yaml:"protected,omitempty" toml:"protected,omitempty"`
+}
+
+// Validate checks the rule's glob and policy
+func (r PolicyRule) Validate() error {
+	if r.Match == "" {
+		return fmt.Errorf("policy rule needs a match pattern")
+	}
+	if err := ValidateGlob(r.Match); err != nil {
+		return fmt.Errorf("invalid policy pattern %q", r.Match)
+	}
+	if !r.Policy.Valid() {
+		return fmt.Errorf("invalid policy %q for %q (use create-only, enforce, or ignore)", r.Policy, r.Match)
+	}
+	return nil
+}
+
+// ApplyPolicies fills in the policy of labels that have none from the first
+// matching rule that sets one, and protects labels matched by any protecting
+// rule
+func ApplyPolicies(labels []Label, rules []PolicyRule) {
+	for i := range labels {
+		for _, rule := range rules {
+			if !MatchGlob(rule.Match, labels[i].Name) {
+				continue
+			}
+			if labels[i].Policy == PolicyDefault {
+				labels[i].Policy = rule.Policy
+			}
+			if rule.Protected {
+				labels[i].Protected = true
+			}
+		}
+	}
+}
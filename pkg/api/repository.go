@@ -0,0 +1,125 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Repository describes the repository or organization a store manages
+type Repository struct {
+	Owner  string
+	Name   string
+	Topics []string
+
+	// Language is the primary language, empty if unknown
+	Language string
+
+	// Visibility is public, private, or internal
+	Visibility string
+}
+
+// Describer is implemented by stores that can describe their repository
+type Describer interface {
+	Repository() (*Repository, error)
+}
+
+// Describe returns the store's repository, or an error if the store cannot
+// describe it
+func Describe(store Store) (*Repository, error) {
+	d, ok := store.(Describer)
+	if !ok {
+		return nil, fmt.Errorf("repository details are not available for this provider")
+	}
+	return d.Repository()
+}
+
+// PrimaryLanguage returns the language with the largest share, for APIs that
+// report a breakdown instead of a primary language
+func PrimaryLanguage(shares map[string]float64) string {
+	var primary string
+	for language, share := range shares {
+		if primary == "" || share > shares[primary] || (share == shares[primary] && language < primary) {
+			primary = language
+		}
+	}
+	return primary
+}
+
+// Repository fetches the repository's owner, name, topics, language, and
+// visibility
+func (c *Client) Repository() (*Repository, error) {
+	var resp struct {
+		Name  string `json:"name"`
+		Owner struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+		Topics     []string `json:"topics"`
+		Language   string   `json:"language"`
+		Visibility string   `json:"visibility"`
+	}
+
+	path := fmt.Sprintf("repos/%s/%s", c.repo.Owner, c.repo.Name)
+	if err := c.restClient.Get(path, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get repository: %w", err)
+	}
+
+	return &Repository{
+		Owner:      resp.Owner.Login,
+		Name:       resp.Name,
+		Topics:     resp.Topics,
+		Language:   resp.Language,
+		Visibility: resp.Visibility,
+	}, nil
+}
+
+// Repository fetches the repository's owner, name, topics, language, and
+// visibility
+func (c *GraphQLClient) Repository() (*Repository, error) {
+	query := `query($owner: String!, $name: String!) {
+		repository(owner: $owner, name: $name) {
+			name
+			owner { login }
+			repositoryTopics(first: 100) { nodes { topic { name } } }
+			primaryLanguage { name }
+			visibility
+		}
+	}`
+
+	var resp struct {
+		Repository struct {
+			Name  string `json:"name"`
+			Owner struct {
+				Login string `json:"login"`
+			} `json:"owner"`
+			RepositoryTopics struct {
+				Nodes []struct {
+					Topic struct {
+						Name string `json:"name"`
+					} `json:"topic"`
+				} `json:"nodes"`
+			} `json:"repositoryTopics"`
+			PrimaryLanguage *struct {
+				Name string `json:"name"`
+			} `json:"primaryLanguage"`
+			Visibility string `json:"visibility"`
+		} `json:"repository"`
+	}
+
+	variables := map[string]interface{}{"owner": c.repo.Owner, "name": c.repo.Name}
+	if err := c.gqlClient.Do(query, variables, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get repository: %w", err)
+	}
+
+	repo := &Repository{
+		Owner:      resp.Repository.Owner.Login,
+		Name:       resp.Repository.Name,
+		Visibility: strings.ToLower(resp.Repository.Visibility),
+	}
+	if resp.Repository.PrimaryLanguage != nil {
+		repo.Language = resp.Repository.PrimaryLanguage.Name
+	}
+	for _, node := range resp.Repository.RepositoryTopics.Nodes {
+		repo.Topics = append(repo.Topics, node.Topic.Name)
+	}
+	return repo, nil
+}
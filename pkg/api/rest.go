@@ -0,0 +1,92 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// JSONClient sends requests to a JSON REST API. It is shared by the backends
+// that do not go through the GitHub CLI's API client.
+type JSONClient struct {
+	HTTPClient *http.Client
+
+	// BaseURL is the API root that request paths are relative to
+	BaseURL string
+
+	// Header is added to every request, e.g. for authentication
+	Header http.Header
+}
+
+// NewJSONClient creates a client for the API at baseURL. A nil transport
+// uses http.DefaultTransport.
+func NewJSONClient(baseURL string, header http.Header, transport http.RoundTripper) *JSONClient {
+	return &JSONClient{
+		HTTPClient: &http.Client{Transport: transport},
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		Header:     header,
+	}
+}
+
+// Do sends a request to the API, encoding body and decoding the response into
+// out when they are non-nil. Error responses are reported with their status
+// and the API's "message" or "error" field.
+func (c *JSONClient) Do(method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal input: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.BaseURL+"/"+path, reader)
+	if err != nil {
+		return err
+	}
+	for key, values := range c.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		// GitLab reports validation errors as a map in "message"
+		var apiErr struct {
+			Message any    `json:"message"`
+			Error   string `json:"error"`
+		}
+		data, _ := io.ReadAll(resp.Body)
+		if json.Unmarshal(data, &apiErr) == nil {
+			if apiErr.Message != nil && apiErr.Message != "" {
+				return fmt.Errorf("HTTP %d: %v", resp.StatusCode, apiErr.Message)
+			}
+			if apiErr.Error != "" {
+				return fmt.Errorf("HTTP %d: %s", resp.StatusCode, apiErr.Error)
+			}
+		}
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
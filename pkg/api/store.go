@@ -0,0 +1,67 @@
+package api
+
+// Store is a backend that holds a repository's labels
+type Store interface {
+	ListLabels() ([]Label, error)
+	CreateLabel(input LabelInput) (*Label, error)
+	UpdateLabel(name string, input LabelInput) (*Label, error)
+	DeleteLabel(name string) error
+}
+
+// OperationKind identifies the change an Operation makes
+type OperationKind string
+
+const (
+	OperationCreate OperationKind = "create"
+	OperationUpdate OperationKind = "update"
+	OperationDelete OperationKind = "delete"
+)
+
+// Operation is a single label change. Name is the current label name for
+// updates and deletes; Input is unused for deletes.
+type Operation struct {
+	Kind  OperationKind
+	Name  string
+	Input LabelInput
+}
+
+// Batcher is implemented by stores that can apply many operations in fewer
+// round trips. The returned slice has one entry per operation, nil on success.
+type Batcher interface {
+	ApplyBatch(ops []Operation) []error
+}
+
+// Field is an optional label field that only some providers support
+type Field string
+
+const (
+	FieldPriority  Field = "priority"
+	FieldExclusive Field = "exclusive"
+)
+
+// FieldSupporter is implemented by stores that support optional fields
+type FieldSupporter interface {
+	SupportsField(field Field) bool
+}
+
+// Supports reports whether store supports an optional field
+func Supports(store Store, field Field) bool {
+	fs, ok := store.(FieldSupporter)
+	return ok && fs.SupportsField(field)
+}
+
+// StripUnsupported clears optional fields the store does not support, so
+// they are neither compared nor sent
+func StripUnsupported(store Store, labels []Label) {
+	priority := Supports(store, FieldPriority)
+	exclusive := Supports(store, FieldExclusive)
+
+	for i := range labels {
+		if !priority {
+			labels[i].Priority = nil
+		}
+		if !exclusive {
+			labels[i].Exclusive = nil
+		}
+	}
+}
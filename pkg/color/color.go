@@ -0,0 +1,123 @@
+package color
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// lightnessThreshold is the perceived lightness above which GitHub renders
+// label text in black rather than white
+const lightnessThreshold = 0.453
+
+// Text colors GitHub chooses between when rendering a label
+var (
+	Black = RGB{0, 0, 0}
+	White = RGB{255, 255, 255}
+)
+
+// RGB is a color with 8-bit red, green, and blue channels
+type RGB struct {
+	R, G, B uint8
+}
+
+// Parse parses a hex color with or without a leading #. Both the 6-digit and
+// the 3-digit shorthand forms are accepted.
+func Parse(hex string) (RGB, error) {
+	s := strings.TrimPrefix(strings.TrimSpace(hex), "#")
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	if len(s) != 6 {
+		return RGB{}, fmt.Errorf("invalid color %q: expected 6 hex digits", hex)
+	}
+
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return RGB{}, fmt.Errorf("invalid color %q: %w", hex, err)
+	}
+
+	return RGB{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v)}, nil
+}
+
+// Hex returns the color as 6 lowercase hex digits without a leading #
+func (c RGB) Hex() string {
+	return fmt.Sprintf("%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// PerceivedLightness returns the lightness of the color in [0, 1] using the
+// same weighting GitHub applies when styling labels
+func (c RGB) PerceivedLightness() float64 {
+	return (float64(c.R)*0.2126 + float64(c.G)*0.7152 + float64(c.B)*0.0722) / 255
+}
+
+// TextColor returns the text color GitHub uses on a label of this color
+func (c RGB) TextColor() RGB {
+	if c.PerceivedLightness() > lightnessThreshold {
+		return Black
+	}
+	return White
+}
+
+// Families are the named hue families a color can belong to
+var Families = []string{"red", "orange", "yellow", "green", "cyan", "blue", "purple", "pink", "gray"}
+
+// graySaturation is the saturation below which a color has no clear hue
+const graySaturation = 0.15
+
+// HSL returns the hue in degrees [0, 360) and the saturation and lightness
+// in [0, 1]
+func (c RGB) HSL() (h, s, l float64) {
+	r, g, b := float64(c.R)/255, float64(c.G)/255, float64(c.B)/255
+	hi, lo := max(r, g, b), min(r, g, b)
+	l = (hi + lo) / 2
+
+	d := hi - lo
+	if d == 0 {
+		return 0, 0, l
+	}
+
+	s = d / (1 - math.Abs(2*l-1))
+	switch hi {
+	case r:
+		h = math.Mod((g-b)/d, 6)
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	h *= 60
+	if h < 0 {
+		h += 360
+	}
+	return h, s, l
+}
+
+// Family returns the hue family of the color, or gray for colors with
+// little saturation
+func (c RGB) Family() string {
+	h, s, l := c.HSL()
+	if s < graySaturation || l < 0.08 || l > 0.95 {
+		return "gray"
+	}
+
+	switch {
+	case h < 15 || h >= 345:
+		return "red"
+	case h < 45:
+		return "orange"
+	case h < 70:
+		return "yellow"
+	case h < 165:
+		return "green"
+	case h < 195:
+		return "cyan"
+	case h < 255:
+		return "blue"
+	case h < 290:
+		return "purple"
+	default:
+		return "pink"
+	}
+}
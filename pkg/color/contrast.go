@@ -0,0 +1,193 @@
+package color
+
+import "math"
+
+// MinContrast is the WCAG AA contrast ratio for normal text
+const MinContrast = 4.5
+
+// Lab is a color in the CIE L*a*b* space, under the D65 white point
+type Lab struct {
+	L, A, B float64
+}
+
+// linear converts an 8-bit sRGB channel to linear light
+func linear(v uint8) float64 {
+	c := float64(v) / 255
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
+
+// Luminance returns the WCAG relative luminance of the color in [0, 1]
+func (c RGB) Luminance() float64 {
+	return 0.2126*linear(c.R) + 0.7152*linear(c.G) + 0.0722*linear(c.B)
+}
+
+// Contrast returns the WCAG contrast ratio between two colors, from 1 to 21
+func Contrast(a, b RGB) float64 {
+	la, lb := a.Luminance(), b.Luminance()
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// TextContrast returns the contrast between the color and the text color
+// GitHub renders on it
+func (c RGB) TextContrast() float64 {
+	return Contrast(c, c.TextColor())
+}
+
+// Lab converts the color to CIE L*a*b*
+func (c RGB) Lab() Lab {
+	r, g, b := linear(c.R), linear(c.G), linear(c.B)
+
+	// sRGB to XYZ, relative to the D65 white point
+	x := (0.4124564*r + 0.3575761*g + 0.1804375*b) / 0.95047
+	y := 0.2126729*r + 0.7151522*g + 0.0721750*b
+	z := (0.0193339*r + 0.1191920*g + 0.9503041*b) / 1.08883
+
+	f := func(t float64) float64 {
+		if t > 216.0/24389 {
+			return math.Cbrt(t)
+		}
+		return (24389.0/27*t + 16) / 116
+	}
+	fx, fy, fz := f(x), f(y), f(z)
+
+	return Lab{L: 116*fy - 16, A: 500 * (fx - fy), B: 200 * (fy - fz)}
+}
+
+// DeltaE returns the CIEDE2000 color difference between two colors. Values
+// below about 2 are barely perceptible.
+func DeltaE(a, b RGB) float64 {
+	return a.Lab().DeltaE(b.Lab())
+}
+
+// DeltaE returns the CIEDE2000 difference between two Lab colors
+func (p Lab) DeltaE(q Lab) float64 {
+	rad := math.Pi / 180
+
+	c1 := math.Hypot(p.A, p.B)
+	c2 := math.Hypot(q.A, q.B)
+	cBar7 := math.Pow((c1+c2)/2, 7)
+	g := 0.5 * (1 - math.Sqrt(cBar7/(cBar7+math.Pow(25, 7))))
+
+	a1, a2 := (1+g)*p.A, (1+g)*q.A
+	c1p, c2p := math.Hypot(a1, p.B), math.Hypot(a2, q.B)
+
+	hue := func(b, a float64) float64 {
+		if a == 0 && b == 0 {
+			return 0
+		}
+		h := math.Atan2(b, a) / rad
+		if h < 0 {
+			h += 360
+		}
+		return h
+	}
+	h1, h2 := hue(p.B, a1), hue(q.B, a2)
+
+	dL := q.L - p.L
+	dC := c2p - c1p
+
+	var dh float64
+	switch {
+	case c1p*c2p == 0:
+		dh = 0
+	case math.Abs(h2-h1) <= 180:
+		dh = h2 - h1
+	case h2-h1 > 180:
+		dh = h2 - h1 - 360
+	default:
+		dh = h2 - h1 + 360
+	}
+	dH := 2 * math.Sqrt(c1p*c2p) * math.Sin(dh/2*rad)
+
+	lBar := (p.L + q.L) / 2
+	cBar := (c1p + c2p) / 2
+
+	var hBar float64
+	switch {
+	case c1p*c2p == 0:
+		hBar = h1 + h2
+	case math.Abs(h1-h2) <= 180:
+		hBar = (h1 + h2) / 2
+	case h1+h2 < 360:
+		hBar = (h1 + h2 + 360) / 2
+	default:
+		hBar = (h1 + h2 - 360) / 2
+	}
+
+	t := 1 - 0.17*math.Cos((hBar-30)*rad) + 0.24*math.Cos(2*hBar*rad) +
+		0.32*math.Cos((3*hBar+6)*rad) - 0.20*math.Cos((4*hBar-63)*rad)
+	dTheta := 30 * math.Exp(-math.Pow((hBar-275)/25, 2))
+	cBarP7 := math.Pow(cBar, 7)
+	rc := 2 * math.Sqrt(cBarP7/(cBarP7+math.Pow(25, 7)))
+	sl := 1 + 0.015*math.Pow(lBar-50, 2)/math.Sqrt(20+math.Pow(lBar-50, 2))
+	sc := 1 + 0.045*cBar
+	sh := 1 + 0.015*cBar*t
+	rt := -math.Sin(2*dTheta*rad) * rc
+
+	l, c, h := dL/sl, dC/sc, dH/sh
+	return math.Sqrt(l*l + c*c + h*h + rt*c*h)
+}
+
+// FromHSL builds a color from a hue in degrees and saturation and lightness
+// in [0, 1]
+func FromHSL(h, s, l float64) RGB {
+	chroma := (1 - math.Abs(2*l-1)) * s
+	hp := math.Mod(h, 360) / 60
+	x := chroma * (1 - math.Abs(math.Mod(hp, 2)-1))
+
+	var r, g, b float64
+	switch {
+	case hp < 1:
+		r, g = chroma, x
+	case hp < 2:
+		r, g = x, chroma
+	case hp < 3:
+		g, b = chroma, x
+	case hp < 4:
+		g, b = x, chroma
+	case hp < 5:
+		r, b = x, chroma
+	default:
+		r, b = chroma, x
+	}
+
+	m := l - chroma/2
+	channel := func(v float64) uint8 {
+		return uint8(math.Round(math.Max(0, math.Min(1, v+m)) * 255))
+	}
+	return RGB{R: channel(r), G: channel(g), B: channel(b)}
+}
+
+// Accessible returns the color closest in lightness to c, with the same hue
+// and saturation, whose GitHub text color has at least the given contrast.
+// It returns c itself if it already has enough contrast.
+func (c RGB) Accessible(minContrast float64) RGB {
+	if c.TextContrast() >= minContrast {
+		return c
+	}
+
+	h, s, l := c.HSL()
+	for step := 1; step <= 100; step++ {
+		// Try darker first, since white text on a darker color usually
+		// keeps the label closest to its original look
+		for _, dl := range []float64{-float64(step) / 100, float64(step) / 100} {
+			nl := l + dl
+			if nl < 0 || nl > 1 {
+				continue
+			}
+			candidate := FromHSL(h, s, nl)
+			if candidate.TextContrast() >= minContrast {
+				return candidate
+			}
+		}
+	}
+
+	// Black always reaches the maximum contrast with white text
+	return Black
+}
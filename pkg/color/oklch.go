@@ -0,0 +1,128 @@
+package color
+
+import "math"
+
+// OKLCH is a color in the OKLCH space: perceptual lightness in [0, 1],
+// chroma from 0 (gray) up to about 0.37, and hue in degrees
+type OKLCH struct {
+	L, C, H float64
+}
+
+// OKLCH converts the color to OKLCH
+func (c RGB) OKLCH() OKLCH {
+	r, g, b := linear(c.R), linear(c.G), linear(c.B)
+
+	l := math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*b)
+	m := math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*b)
+	s := math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*b)
+
+	L := 0.2104542553*l + 0.7936177850*m - 0.0040720468*s
+	A := 1.9779984951*l - 2.4285922050*m + 0.4505937099*s
+	B := 0.0259040371*l + 0.7827717662*m - 0.8086757660*s
+
+	h := math.Atan2(B, A) * 180 / math.Pi
+	if h < 0 {
+		h += 360
+	}
+	return OKLCH{L: L, C: math.Hypot(A, B), H: h}
+}
+
+// linearRGB converts the color to linear sRGB, whose channels fall outside
+// [0, 1] when the color is out of gamut
+func (o OKLCH) linearRGB() (r, g, b float64) {
+	rad := o.H * math.Pi / 180
+	A, B := o.C*math.Cos(rad), o.C*math.Sin(rad)
+
+	l := math.Pow(o.L+0.3963377774*A+0.2158037573*B, 3)
+	m := math.Pow(o.L-0.1055613458*A-0.0638541728*B, 3)
+	s := math.Pow(o.L-0.0894841775*A-1.2914855480*B, 3)
+
+	r = 4.0767416621*l - 3.3077115913*m + 0.2309699292*s
+	g = -1.2684380046*l + 2.6097574011*m - 0.3413193965*s
+	b = -0.0041960863*l - 0.7034186147*m + 1.7076147010*s
+	return r, g, b
+}
+
+func (o OKLCH) inGamut() bool {
+	const eps = 1e-6
+	r, g, b := o.linearRGB()
+	return r >= -eps && r <= 1+eps && g >= -eps && g <= 1+eps && b >= -eps && b <= 1+eps
+}
+
+// RGB converts the color to sRGB. Colors outside the sRGB gamut keep their
+// lightness and hue and lose chroma until they fit.
+func (o OKLCH) RGB() RGB {
+	if !o.inGamut() {
+		lo, hi := 0.0, o.C
+		for i := 0; i < 30; i++ {
+			o.C = (lo + hi) / 2
+			if o.inGamut() {
+				lo = o.C
+			} else {
+				hi = o.C
+			}
+		}
+		o.C = lo
+	}
+
+	r, g, b := o.linearRGB()
+	return RGB{R: encode(r), G: encode(g), B: encode(b)}
+}
+
+// encode converts a linear channel to 8-bit sRGB
+func encode(v float64) uint8 {
+	v = math.Max(0, math.Min(1, v))
+	if v <= 0.0031308 {
+		v *= 12.92
+	} else {
+		v = 1.055*math.Pow(v, 1/2.4) - 0.055
+	}
+	return uint8(math.Round(v * 255))
+}
+
+// Distinct returns n colors of the given OKLCH lightness and chroma with hues
+// evenly spaced around the color wheel, starting at hue
+func Distinct(n int, lightness, chroma, hue float64) []RGB {
+	colors := make([]RGB, n)
+	for i := range colors {
+		h := math.Mod(hue+float64(i)*360/float64(n), 360)
+		colors[i] = OKLCH{L: lightness, C: chroma, H: h}.RGB()
+	}
+	return colors
+}
+
+// Gradient returns n colors stepping evenly from one color to another in
+// OKLCH, taking the shorter way around the hue circle
+func Gradient(from, to RGB, n int) []RGB {
+	if n == 1 {
+		return []RGB{from}
+	}
+
+	a, b := from.OKLCH(), to.OKLCH()
+
+	// Grays have no meaningful hue, so take the other end's
+	if a.C < 1e-4 {
+		a.H = b.H
+	}
+	if b.C < 1e-4 {
+		b.H = a.H
+	}
+	dh := b.H - a.H
+	if dh > 180 {
+		dh -= 360
+	} else if dh < -180 {
+		dh += 360
+	}
+
+	colors := make([]RGB, n)
+	for i := range colors {
+		t := float64(i) / float64(n-1)
+		colors[i] = OKLCH{
+			L: a.L + t*(b.L-a.L),
+			C: a.C + t*(b.C-a.C),
+			H: math.Mod(a.H+t*dh+360, 360),
+		}.RGB()
+	}
+	colors[0], colors[n-1] = from, to
+	return colors
+}
@@ -0,0 +1,279 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/scttfrdmn/gh-label-sync/pkg/api"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the repository-local config file
+const FileName = ".label-sync.yml"
+
+// Settings are flag defaults and sync scope from a config file. Unset fields
+// leave the built-in defaults in place.
+type Settings struct {
+	File            string `yaml:"file"`
+	InputFormat     string `yaml:"input-format"`
+	Force           *bool  `yaml:"force"`
+	DeleteUnmanaged *bool  `yaml:"delete-unmanaged"`
+	Provider        string `yaml:"provider"`
+	Hostname        string `yaml:"hostname"`
+	API             string `yaml:"api"`
+	Sort            string `yaml:"sort"`
+	Group           *bool  `yaml:"group"`
+
+	// Repos are the repositories sync targets when --repo is not given
+	Repos []string `yaml:"repos"`
+
+	// Include and Exclude are label name globs limiting which labels are
+	// managed. Labels outside them are neither changed nor deleted.
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
+}
+
+// Config holds default settings and named profiles
+type Config struct {
+	Defaults Settings            `yaml:"defaults"`
+	Profiles map[string]Settings `yaml:"profiles"`
+
+	// Paths lists the config files that were loaded, lowest precedence first
+	Paths []string `yaml:"-"`
+}
+
+// UserPath returns the user-level config file, under $XDG_CONFIG_HOME or
+// ~/.config
+func UserPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "gh-label-sync", "config.yml")
+}
+
+// FindLocal looks for the repository-local config file in the current
+// directory and its parents, stopping at the repository root. It returns an
+// empty string if there is none.
+func FindLocal() string {
+	dir := "."
+	for {
+		path := filepath.Join(dir, FileName)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+
+		// Stop at the top of the repository or the file system
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return ""
+		}
+		abs, err := filepath.Abs(dir)
+		if err != nil || filepath.Dir(abs) == abs {
+			return ""
+		}
+		dir = filepath.Join(dir, "..")
+	}
+}
+
+// Load reads the user-level config and then the repository-local one, which
+// takes precedence. Missing files are not an error.
+func Load() (*Config, error) {
+	cfg := &Config{Profiles: make(map[string]Settings)}
+
+	for _, path := range []string{UserPath(), FindLocal()} {
+		if path == "" {
+			continue
+		}
+		loaded, err := loadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		cfg.merge(loaded)
+		cfg.Paths = append(cfg.Paths, path)
+	}
+
+	return cfg, nil
+}
+
+func loadFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	for _, s := range append([]Settings{cfg.Defaults}, profileSettings(cfg.Profiles)...) {
+		for _, pattern := range append(s.Include, s.Exclude...) {
+			if err := api.ValidateGlob(pattern); err != nil {
+				return nil, fmt.Errorf("invalid label pattern %q in %s", pattern, path)
+			}
+		}
+	}
+
+	// Label files are relative to the config file that names them
+	dir := filepath.Dir(path)
+	cfg.Defaults.File = relativeTo(dir, cfg.Defaults.File)
+	for name, s := range cfg.Profiles {
+		s.File = relativeTo(dir, s.File)
+		cfg.Profiles[name] = s
+	}
+
+	return &cfg, nil
+}
+
+func profileSettings(profiles map[string]Settings) []Settings {
+	settings := make([]Settings, 0, len(profiles))
+	for _, s := range profiles {
+		settings = append(settings, s)
+	}
+	return settings
+}
+
+func relativeTo(dir, file string) string {
+	if file == "" || file == "-" || filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(dir, file)
+}
+
+// merge layers another config over this one. Defaults merge field by field;
+// a profile replaces one of the same name.
+func (c *Config) merge(other *Config) {
+	c.Defaults = c.Defaults.Merge(other.Defaults)
+	for name, s := range other.Profiles {
+		c.Profiles[name] = s
+	}
+}
+
+// Resolve returns the defaults with the named profile layered over them. An
+// empty name returns the defaults.
+func (c *Config) Resolve(profile string) (Settings, error) {
+	if profile == "" {
+		return c.Defaults, nil
+	}
+
+	s, ok := c.Profiles[profile]
+	if !ok {
+		if len(c.Profiles) == 0 {
+			return Settings{}, fmt.Errorf("unknown profile %q (no profiles configured)", profile)
+		}
+		return Settings{}, fmt.Errorf("unknown profile %q (available: %s)", profile, strings.Join(c.ProfileNames(), ", "))
+	}
+
+	return c.Defaults.Merge(s), nil
+}
+
+// ProfileNames returns the configured profile names in order
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Merge returns s with every field set in over replacing its own
+func (s Settings) Merge(over Settings) Settings {
+	if over.File != "" {
+		s.File = over.File
+	}
+	if over.InputFormat != "" {
+		s.InputFormat = over.InputFormat
+	}
+	if over.Force != nil {
+		s.Force = over.Force
+	}
+	if over.DeleteUnmanaged != nil {
+		s.DeleteUnmanaged = over.DeleteUnmanaged
+	}
+	if over.Provider != "" {
+		s.Provider = over.Provider
+	}
+	if over.Hostname != "" {
+		s.Hostname = over.Hostname
+	}
+	if over.API != "" {
+		s.API = over.API
+	}
+	if over.Sort != "" {
+		s.Sort = over.Sort
+	}
+	if over.Group != nil {
+		s.Group = over.Group
+	}
+	if over.Repos != nil {
+		s.Repos = over.Repos
+	}
+	if over.Include != nil {
+		s.Include = over.Include
+	}
+	if over.Exclude != nil {
+		s.Exclude = over.Exclude
+	}
+	return s
+}
+
+// FlagValues returns the settings that correspond to command-line flags,
+// keyed by flag name
+func (s Settings) FlagValues() map[string]string {
+	values := make(map[string]string)
+	set := func(name, value string) {
+		if value != "" {
+			values[name] = value
+		}
+	}
+
+	set("file", s.File)
+	set("input-format", s.InputFormat)
+	set("provider", s.Provider)
+	set("hostname", s.Hostname)
+	set("api", s.API)
+	set("sort", s.Sort)
+	if s.Force != nil {
+		values["force"] = strconv.FormatBool(*s.Force)
+	}
+	if s.DeleteUnmanaged != nil {
+		values["delete-unmanaged"] = strconv.FormatBool(*s.DeleteUnmanaged)
+	}
+	if s.Group != nil {
+		values["group"] = strconv.FormatBool(*s.Group)
+	}
+
+	return values
+}
+
+// Manages reports whether a label name is within the include and exclude
+// globs. With no include globs every label is included.
+func (s Settings) Manages(name string) bool {
+	if len(s.Include) > 0 && !matchAny(s.Include, name) {
+		return false
+	}
+	return !matchAny(s.Exclude, name)
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if api.MatchGlob(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
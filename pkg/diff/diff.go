@@ -1,6 +1,9 @@
 package diff
 
 import (
+	"slices"
+	"strings"
+
 	"github.com/scttfrdmn/gh-label-sync/pkg/api"
 )
 
@@ -11,18 +14,37 @@ const (
 	DiffTypeUpdate DiffType = "update"
 	DiffTypeMatch  DiffType = "match"
 	DiffTypeExtra  DiffType = "extra"
+
+	// DiffTypeRename is a repository label matched through one of the desired
+	// label's aliases; it is renamed and brought in line with the file
+	DiffTypeRename DiffType = "rename"
+
+	// DiffTypeDelete is a repository label the file marks for deletion
+	DiffTypeDelete DiffType = "delete"
 )
 
 type LabelDiff struct {
-	Type        DiffType
-	Name        string
-	Desired     *api.Label
-	Current     *api.Label
-	ColorChange bool
-	DescChange  bool
+	Type            DiffType
+	Name            string
+	Desired         *api.Label
+	Current         *api.Label
+	ColorChange     bool
+	DescChange      bool
+	PriorityChange  bool
+	ExclusiveChange bool
+
+	// Excluded marks a repository-only label that the file defines for
+	// other repositories, behind a when condition this one does not meet.
+	// It is kept, even with --delete-unmanaged.
+	Excluded bool
 }
 
-// ComputeDiff compares desired labels with current labels
+// ComputeDiff compares desired labels with current labels. A desired label
+// that is missing from the repository is matched against its aliases before
+// being created, and labels marked for deletion are deleted if present.
+// Labels with the ignore policy, on either side, are left out. Diffs follow
+// the desired labels' order, then repository-only labels sorted by name, so
+// the result does not depend on the order the provider lists labels in.
 func ComputeDiff(desired, current []api.Label) []LabelDiff {
 	var diffs []LabelDiff
 
@@ -34,33 +56,44 @@ func ComputeDiff(desired, current []api.Label) []LabelDiff {
 
 	desiredMap := make(map[string]api.Label)
 	for _, label := range desired {
-		desiredMap[label.Name] = label
+		if !label.Delete {
+			desiredMap[label.Name] = label
+		}
 	}
 
+	// managed holds the current labels the file accounts for
+	managed := make(map[string]bool)
+
 	// Check desired labels
 	for _, desiredLabel := range desired {
-		if currentLabel, exists := currentMap[desiredLabel.Name]; exists {
-			// Label exists, check if it matches
-			colorMatch := api.NormalizeColor(currentLabel.Color) == api.NormalizeColor(desiredLabel.Color)
-			descMatch := currentLabel.Description == desiredLabel.Description
+		if desiredLabel.Policy == api.PolicyIgnore {
+			managed[desiredLabel.Name] = true
+			continue
+		}
 
-			if colorMatch && descMatch {
+		if desiredLabel.Delete {
+			managed[desiredLabel.Name] = true
+			if currentLabel, exists := currentMap[desiredLabel.Name]; exists {
 				diffs = append(diffs, LabelDiff{
-					Type:    DiffTypeMatch,
+					Type:    DiffTypeDelete,
 					Name:    desiredLabel.Name,
 					Desired: &desiredLabel,
 					Current: &currentLabel,
 				})
-			} else {
-				diffs = append(diffs, LabelDiff{
-					Type:        DiffTypeUpdate,
-					Name:        desiredLabel.Name,
-					Desired:     &desiredLabel,
-					Current:     &currentLabel,
-					ColorChange: !colorMatch,
-					DescChange:  !descMatch,
-				})
 			}
+			continue
+		}
+
+		currentLabel, exists := currentMap[desiredLabel.Name]
+		renamed := false
+		if !exists {
+			currentLabel, renamed = findAlias(desiredLabel, currentMap, desiredMap, managed)
+			exists = renamed
+		}
+
+		if exists {
+			managed[currentLabel.Name] = true
+			diffs = append(diffs, compare(desiredLabel, currentLabel, renamed))
 		} else {
 			// Label doesn't exist, needs to be created
 			diffs = append(diffs, LabelDiff{
@@ -72,32 +105,181 @@ func ComputeDiff(desired, current []api.Label) []LabelDiff {
 	}
 
 	// Check for extra labels (in repo but not in file)
+	var extras []LabelDiff
 	for _, currentLabel := range current {
-		if _, exists := desiredMap[currentLabel.Name]; !exists {
-			diffs = append(diffs, LabelDiff{
+		if !managed[currentLabel.Name] && currentLabel.Policy != api.PolicyIgnore {
+			extras = append(extras, LabelDiff{
 				Type:    DiffTypeExtra,
 				Name:    currentLabel.Name,
 				Current: &currentLabel,
 			})
 		}
 	}
+	slices.SortStableFunc(extras, func(a, b LabelDiff) int { return compareNames(a.Name, b.Name) })
+	diffs = append(diffs, extras...)
 
 	return diffs
 }
 
+// compare diffs a desired label against the repository label it manages.
+// A renamed label is always a rename, whether or not its fields differ.
+func compare(desired, current api.Label, renamed bool) LabelDiff {
+	colorMatch := api.NormalizeColor(current.Color) == api.NormalizeColor(desired.Color)
+	descMatch := current.Description == desired.Description
+	priorityMatch := desired.Priority == nil ||
+		(current.Priority != nil && *current.Priority == *desired.Priority)
+	// Backends leave Exclusive unset for labels that are not exclusive
+	exclusiveMatch := desired.Exclusive == nil ||
+		(current.Exclusive != nil && *current.Exclusive) == *desired.Exclusive
+
+	if !renamed && colorMatch && descMatch && priorityMatch && exclusiveMatch {
+		return LabelDiff{
+			Type:    DiffTypeMatch,
+			Name:    desired.Name,
+			Desired: &desired,
+			Current: &current,
+		}
+	}
+
+	d := LabelDiff{
+		Type:            DiffTypeUpdate,
+		Name:            desired.Name,
+		Desired:         &desired,
+		Current:         &current,
+		ColorChange:     !colorMatch,
+		DescChange:      !descMatch,
+		PriorityChange:  !priorityMatch,
+		ExclusiveChange: !exclusiveMatch,
+	}
+	if renamed {
+		d.Type = DiffTypeRename
+	}
+	return d
+}
+
+// findAlias returns the first current label named by one of the desired
+// label's aliases. Labels that are themselves desired, or already matched,
+// are never renamed.
+func findAlias(desired api.Label, current, desiredMap map[string]api.Label, managed map[string]bool) (api.Label, bool) {
+	for _, alias := range desired.Aliases {
+		if _, ok := desiredMap[alias]; ok || managed[alias] {
+			continue
+		}
+		if label, ok := current[alias]; ok {
+			return label, true
+		}
+	}
+	return api.Label{}, false
+}
+
+// Counts holds the number of diffs of each type. Updates and extras that a
+// label's policy decides on are counted separately from those that follow
+// the command-line flags.
+type Counts struct {
+	Matches int
+	Creates int
+	Updates int
+	Renames int
+	Extras  int
+	Deletes int
+
+	// Enforced are updates applied regardless of --force
+	Enforced int
+
+	// Kept are updates and deletions a policy or protection prevents
+	Kept int
+
+	// Excluded are repository labels the file only defines for other
+	// repositories
+	Excluded int
+}
+
+// Changes returns the number of diffs that could be applied
+func (c Counts) Changes() int {
+	return c.Creates + c.Updates + c.Enforced + c.Renames + c.Extras + c.Deletes
+}
+
 // Summary returns counts for each diff type
-func Summary(diffs []LabelDiff) (matches, creates, updates, extras int) {
+func Summary(diffs []LabelDiff) Counts {
+	var c Counts
 	for _, diff := range diffs {
+		if diff.Excluded {
+			c.Excluded++
+			continue
+		}
+		if Blocked(diff) {
+			c.Kept++
+			continue
+		}
 		switch diff.Type {
 		case DiffTypeMatch:
-			matches++
+			c.Matches++
 		case DiffTypeCreate:
-			creates++
+			c.Creates++
 		case DiffTypeUpdate:
-			updates++
+			if diff.Desired.Policy == api.PolicyEnforce {
+				c.Enforced++
+			} else {
+				c.Updates++
+			}
+		case DiffTypeRename:
+			c.Renames++
 		case DiffTypeExtra:
-			extras++
+			c.Extras++
+		case DiffTypeDelete:
+			c.Deletes++
+		}
+	}
+	return c
+}
+
+// Blocked reports whether a label's policy or protection prevents the diff
+// from being applied, whatever the flags
+func Blocked(d LabelDiff) bool {
+	switch d.Type {
+	case DiffTypeUpdate:
+		return d.Desired.Policy == api.PolicyCreateOnly
+	case DiffTypeExtra, DiffTypeDelete:
+		return d.Current.Protected || (d.Desired != nil && d.Desired.Protected)
+	}
+	return false
+}
+
+// MarkExcluded marks the repository-only labels that are defined by one of
+// the excluded labels, those whose conditions the repository does not meet.
+// Names are compared ignoring case, as GitHub does.
+func MarkExcluded(diffs []LabelDiff, excluded []api.Label) {
+	for i, d := range diffs {
+		if d.Type != DiffTypeExtra {
+			continue
+		}
+		diffs[i].Excluded = slices.ContainsFunc(excluded, func(l api.Label) bool {
+			return strings.EqualFold(l.Name, d.Name)
+		})
+	}
+}
+
+// Pending returns the diffs that would be applied with the given flags.
+// Renames and deletions are explicit in the file, so they are always applied.
+// Label policies take precedence over the flags.
+func Pending(diffs []LabelDiff, force, deleteUnmanaged bool) []LabelDiff {
+	var pending []LabelDiff
+	for _, d := range diffs {
+		if Blocked(d) || d.Excluded {
+			continue
+		}
+		switch d.Type {
+		case DiffTypeCreate, DiffTypeRename, DiffTypeDelete:
+			pending = append(pending, d)
+		case DiffTypeUpdate:
+			if force || d.Desired.Policy == api.PolicyEnforce {
+				pending = append(pending, d)
+			}
+		case DiffTypeExtra:
+			if deleteUnmanaged {
+				pending = append(pending, d)
+			}
 		}
 	}
-	return
+	return pending
 }
@@ -0,0 +1,111 @@
+package diff
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/scttfrdmn/gh-label-sync/pkg/api"
+)
+
+// Order is how diffs are sorted for display
+type Order string
+
+const (
+	// OrderFile keeps labels in file order, followed by repository-only
+	// labels by name
+	OrderFile Order = "file"
+
+	// OrderName sorts labels by name, ignoring case
+	OrderName Order = "name"
+
+	// OrderType sorts labels by change: creates, renames, updates, deletes,
+	// unmanaged labels, then matches, each in file order
+	OrderType Order = "type"
+)
+
+// typeRank is the position of each diff type in OrderType
+var typeRank = map[DiffType]int{
+	DiffTypeCreate: 0,
+	DiffTypeRename: 1,
+	DiffTypeUpdate: 2,
+	DiffTypeDelete: 3,
+	DiffTypeExtra:  4,
+	DiffTypeMatch:  5,
+}
+
+// ParseOrder parses a --sort value. Empty means OrderFile.
+func ParseOrder(s string) (Order, error) {
+	switch Order(s) {
+	case "", OrderFile:
+		return OrderFile, nil
+	case OrderName, OrderType:
+		return Order(s), nil
+	}
+	return "", fmt.Errorf("unsupported sort order: %s (use file, name, or type)", s)
+}
+
+// Sort orders diffs in place. With group set, labels sharing a prefix are
+// kept together, groups sorted by prefix and unprefixed labels last. The
+// sort is stable, so the same input always gives the same output.
+func Sort(diffs []LabelDiff, order Order, group bool) {
+	slices.SortStableFunc(diffs, func(a, b LabelDiff) int {
+		if group {
+			scopeA, _ := api.SplitScope(a.Name)
+			scopeB, _ := api.SplitScope(b.Name)
+			if c := comparePrefixes(scopeA, scopeB); c != 0 {
+				return c
+			}
+		}
+		switch order {
+		case OrderName:
+			return compareNames(a.Name, b.Name)
+		case OrderType:
+			return typeRank[a.Type] - typeRank[b.Type]
+		}
+		return 0
+	})
+}
+
+// Group is a run of diffs whose labels share a prefix
+type Group struct {
+	// Prefix is empty for labels without one
+	Prefix string
+	Diffs  []LabelDiff
+}
+
+// Groups splits diffs sorted with grouping into their prefix groups
+func Groups(diffs []LabelDiff) []Group {
+	var groups []Group
+	for _, d := range diffs {
+		prefix, _ := api.SplitScope(d.Name)
+		if n := len(groups); n > 0 && groups[n-1].Prefix == prefix {
+			groups[n-1].Diffs = append(groups[n-1].Diffs, d)
+			continue
+		}
+		groups = append(groups, Group{Prefix: prefix, Diffs: []LabelDiff{d}})
+	}
+	return groups
+}
+
+// comparePrefixes orders prefixes alphabetically, with no prefix last
+func comparePrefixes(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+	return strings.Compare(a, b)
+}
+
+// compareNames orders label names ignoring case, falling back to the exact
+// names so that the order is total
+func compareNames(a, b string) int {
+	if c := strings.Compare(strings.ToLower(a), strings.ToLower(b)); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
+}
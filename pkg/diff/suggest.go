@@ -0,0 +1,202 @@
+package diff
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/scttfrdmn/gh-label-sync/pkg/api"
+)
+
+// MinNameSimilarity is the similarity, from 0 to 1, that two names must
+// exceed for a label to be suggested as a rename on its name alone. One edit
+// in a five-letter name is not enough.
+const MinNameSimilarity = 0.8
+
+// RenameSuggestion is a label to be created that is likely an unmanaged
+// repository label under a new name
+type RenameSuggestion struct {
+	// From is the repository label and To the label in the file
+	From string
+	To   string
+
+	// Score ranks suggestions, from 0 to 1
+	Score float64
+
+	// NameSimilarity is 1 for names that differ only in case, spacing,
+	// punctuation, or a "prefix:" or "prefix/" scope. Names in the same scope
+	// are scored on the part after it.
+	NameSimilarity float64
+
+	// SameColor and SameDescription report matching attributes. Empty
+	// descriptions never match.
+	SameColor       bool
+	SameDescription bool
+}
+
+// Reason explains why the labels look like a rename
+func (s RenameSuggestion) Reason() string {
+	var reasons []string
+	if s.NameSimilarity > MinNameSimilarity {
+		reasons = append(reasons, "similar name")
+	}
+	switch {
+	case s.SameColor && s.SameDescription:
+		reasons = append(reasons, "same color and description")
+	case s.SameColor:
+		reasons = append(reasons, "same color")
+	case s.SameDescription:
+		reasons = append(reasons, "same description")
+	}
+	return strings.Join(reasons, ", ")
+}
+
+// Confident reports whether the suggestion rests on more than a similar name,
+// so that it can be accepted without asking
+func (s RenameSuggestion) Confident() bool {
+	return s.SameColor || s.SameDescription
+}
+
+// SuggestRenames pairs labels to be created with unmanaged repository labels
+// that look like their old names: names with an edit distance that is small
+// for their length, or the same color and description. Each label appears in
+// at most one suggestion, best scores first. Protected labels are never
+// suggested.
+func SuggestRenames(diffs []LabelDiff) []RenameSuggestion {
+	var creates, extras []LabelDiff
+	for _, d := range diffs {
+		switch {
+		case d.Type == DiffTypeCreate:
+			creates = append(creates, d)
+		case d.Type == DiffTypeExtra && !Blocked(d) && !d.Excluded:
+			extras = append(extras, d)
+		}
+	}
+
+	var candidates []RenameSuggestion
+	for _, c := range creates {
+		for _, e := range extras {
+			s := RenameSuggestion{
+				From:            e.Name,
+				To:              c.Name,
+				NameSimilarity:  nameSimilarity(e.Name, c.Name),
+				SameColor:       api.NormalizeColor(e.Current.Color) == api.NormalizeColor(c.Desired.Color),
+				SameDescription: e.Current.Description != "" && e.Current.Description == c.Desired.Description,
+			}
+			if s.NameSimilarity <= MinNameSimilarity && !(s.SameColor && s.SameDescription) {
+				continue
+			}
+			s.Score = 0.6 * s.NameSimilarity
+			if s.SameColor {
+				s.Score += 0.15
+			}
+			if s.SameDescription {
+				s.Score += 0.25
+			}
+			candidates = append(candidates, s)
+		}
+	}
+
+	// Ties keep diff order, so the result is deterministic
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Score > candidates[j].Score })
+
+	var suggestions []RenameSuggestion
+	used := make(map[string]bool)
+	for _, s := range candidates {
+		if used["from:"+s.From] || used["to:"+s.To] {
+			continue
+		}
+		used["from:"+s.From] = true
+		used["to:"+s.To] = true
+		suggestions = append(suggestions, s)
+	}
+	return suggestions
+}
+
+// ApplyRename replaces the create and extra diffs of a suggestion with a
+// rename of the repository label. The rename takes the create's place.
+func ApplyRename(diffs []LabelDiff, s RenameSuggestion) []LabelDiff {
+	var current *api.Label
+	for _, d := range diffs {
+		if d.Type == DiffTypeExtra && d.Name == s.From {
+			current = d.Current
+		}
+	}
+	if current == nil {
+		return diffs
+	}
+
+	var result []LabelDiff
+	for _, d := range diffs {
+		switch {
+		case d.Type == DiffTypeExtra && d.Name == s.From:
+			continue
+		case d.Type == DiffTypeCreate && d.Name == s.To:
+			d = compare(*d.Desired, *current, true)
+		}
+		result = append(result, d)
+	}
+	return result
+}
+
+// nameSimilarity scores two label names from 0 to 1 by edit distance, after
+// ignoring case, spacing, and punctuation. Names in the same scope are scored
+// on the rest alone, so "priority: 1" and "priority: 2" do not match, and
+// names in different scopes are also compared without them, so "enhancement"
+// matches "type: enhancement".
+func nameSimilarity(a, b string) float64 {
+	scopeA, restA := api.SplitScope(a)
+	scopeB, restB := api.SplitScope(b)
+	if scopeA != "" && scopeA == scopeB {
+		return similarity(normalizeName(restA), normalizeName(restB))
+	}
+
+	score := similarity(normalizeName(a), normalizeName(b))
+	if restA != a || restB != b {
+		score = max(score, similarity(normalizeName(restA), normalizeName(restB)))
+	}
+	return score
+}
+
+// normalizeName lowercases a name and drops everything but letters and digits
+func normalizeName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// similarity is 1 minus the edit distance of a and b relative to the longer
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 0
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein returns the number of single-rune insertions, deletions, and
+// substitutions that turn a into b
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,56 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/scttfrdmn/gh-label-sync/pkg/api"
+)
+
+func TestNameSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b    string
+		similar bool
+	}{
+		{"wontfix", "won't fix", true},
+		{"enhancement", "type: enhancement", true},
+		{"Type: Bug", "type/bug", true},
+		{"priority: 1", "priority: 2", false},
+		{"size/XS", "size/S", false},
+		{"area: frontend", "area: frontends", true},
+		{"docs", "doc", false},
+		{"fixed", "fixes", false},
+	}
+	for _, tt := range tests {
+		score := nameSimilarity(tt.a, tt.b)
+		if got := score > MinNameSimilarity; got != tt.similar {
+			t.Errorf("nameSimilarity(%q, %q) = %.2f, want similar = %v", tt.a, tt.b, score, tt.similar)
+		}
+	}
+}
+
+func TestSuggestRenames(t *testing.T) {
+	create := func(name, color string) LabelDiff {
+		return LabelDiff{Type: DiffTypeCreate, Name: name, Desired: &api.Label{Name: name, Color: color}}
+	}
+	extra := func(name, color string) LabelDiff {
+		return LabelDiff{Type: DiffTypeExtra, Name: name, Current: &api.Label{Name: name, Color: color}}
+	}
+
+	suggestions := SuggestRenames([]LabelDiff{
+		create("type: enhancement", "a2eeef"),
+		create("priority: 2", "fbca04"),
+		create("documentation", "0075ca"),
+		extra("enhancement", "A2EEEF"),
+		extra("priority: 1", "fbca04"),
+		extra("documentations", "ffffff"),
+	})
+	if len(suggestions) != 2 {
+		t.Fatalf("got %d suggestions, want 2: %+v", len(suggestions), suggestions)
+	}
+	if s := suggestions[0]; s.From != "enhancement" || !s.Confident() {
+		t.Errorf("first suggestion = %+v, want a confident rename from enhancement", s)
+	}
+	if s := suggestions[1]; s.From != "documentations" || s.Confident() {
+		t.Errorf("second suggestion = %+v, want a name-only rename from documentations", s)
+	}
+}
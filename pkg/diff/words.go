@@ -0,0 +1,93 @@
+package diff
+
+import (
+	"unicode"
+)
+
+// WordOp identifies how a run of words changed between two strings
+type WordOp string
+
+const (
+	WordEqual  WordOp = "equal"
+	WordDelete WordOp = "delete"
+	WordInsert WordOp = "insert"
+)
+
+// WordChange is a run of text that is kept, removed, or added
+type WordChange struct {
+	Op   WordOp
+	Text string
+}
+
+// DiffWords computes a word-level diff between two strings. Whitespace is
+// kept as separate tokens so that joining the equal and deleted runs yields
+// the old string, and joining the equal and inserted runs yields the new one.
+func DiffWords(old, new string) []WordChange {
+	a, b := splitWords(old), splitWords(new)
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var changes []WordChange
+	add := func(op WordOp, text string) {
+		if n := len(changes); n > 0 && changes[n-1].Op == op {
+			changes[n-1].Text += text
+			return
+		}
+		changes = append(changes, WordChange{Op: op, Text: text})
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			add(WordEqual, a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			add(WordDelete, a[i])
+			i++
+		default:
+			add(WordInsert, b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		add(WordDelete, a[i])
+	}
+	for ; j < len(b); j++ {
+		add(WordInsert, b[j])
+	}
+
+	return changes
+}
+
+// splitWords splits s into alternating runs of whitespace and non-whitespace
+func splitWords(s string) []string {
+	var words []string
+	start, inSpace := 0, false
+	for i, r := range s {
+		space := unicode.IsSpace(r)
+		if i > start && space != inSpace {
+			words = append(words, s[start:i])
+			start = i
+		}
+		inSpace = space
+	}
+	if start < len(s) {
+		words = append(words, s[start:])
+	}
+	return words
+}
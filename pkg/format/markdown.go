@@ -0,0 +1,135 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/scttfrdmn/gh-label-sync/pkg/api"
+	"github.com/scttfrdmn/gh-label-sync/pkg/diff"
+	"github.com/scttfrdmn/gh-label-sync/pkg/markdown"
+)
+
+// FormatMarkdown formats a diff and its summary as a Markdown table, suitable
+// for a GitHub Actions job summary or a pull request comment. With group set,
+// there is a table per label prefix; diffs are expected to be sorted with
+// grouping, as by diff.Sort.
+func FormatMarkdown(diffs []diff.LabelDiff, force, deleteUnmanaged, group bool) string {
+	var sb strings.Builder
+
+	sb.WriteString("### Label sync plan\n\n")
+
+	c := diff.Summary(diffs)
+	if c.Changes() == 0 {
+		sb.WriteString(fmt.Sprintf("✅ All %d label(s) are in sync.\n\n", c.Matches))
+		return sb.String()
+	}
+
+	groups := []diff.Group{{Diffs: diffs}}
+	if group {
+		groups = diff.Groups(diffs)
+	}
+	tables := 0
+	for _, g := range groups {
+		var rows []string
+		for _, d := range g.Diffs {
+			if row := markdownDiffRow(d, force, deleteUnmanaged); row != "" {
+				rows = append(rows, row)
+			}
+		}
+		if len(rows) == 0 {
+			continue
+		}
+
+		if group {
+			heading := g.Prefix
+			if heading == "" {
+				heading = "(no prefix)"
+			}
+			if tables > 0 {
+				sb.WriteString("\n")
+			}
+			sb.WriteString(fmt.Sprintf("#### %s (%d)\n\n", markdown.Escape(heading), len(rows)))
+		}
+		sb.WriteString("| | Label | Color | Description | Change |\n")
+		sb.WriteString("|---|---|---|---|---|\n")
+		for _, row := range rows {
+			sb.WriteString(row)
+		}
+		tables++
+	}
+
+	sb.WriteString(fmt.Sprintf("\n%d match, %d to create, %d differ, %d unmanaged", c.Matches, c.Creates, c.Updates, c.Extras))
+	if c.Enforced > 0 {
+		sb.WriteString(fmt.Sprintf(", %d enforced", c.Enforced))
+	}
+	if c.Renames > 0 {
+		sb.WriteString(fmt.Sprintf(", %d to rename", c.Renames))
+	}
+	if c.Deletes > 0 {
+		sb.WriteString(fmt.Sprintf(", %d to delete", c.Deletes))
+	}
+	if c.Kept > 0 {
+		sb.WriteString(fmt.Sprintf(", %d kept by policy", c.Kept))
+	}
+	if c.Excluded > 0 {
+		sb.WriteString(fmt.Sprintf(", %d excluded by a condition", c.Excluded))
+	}
+	sb.WriteString("\n\n")
+
+	return sb.String()
+}
+
+// markdownDiffRow formats one diff as a table row, or returns "" for a match
+func markdownDiffRow(d diff.LabelDiff, force, deleteUnmanaged bool) string {
+	switch d.Type {
+	case diff.DiffTypeCreate:
+		return markdownRow("➕", d.Name, markdown.Swatch(d.Desired.Color), d.Desired.Description, "create")
+	case diff.DiffTypeUpdate, diff.DiffTypeRename:
+		colorCell := markdown.Swatch(d.Desired.Color)
+		if d.ColorChange {
+			colorCell = markdown.Swatch(d.Current.Color) + " → " + markdown.Swatch(d.Desired.Color)
+		}
+		descCell := markdown.Escape(d.Desired.Description)
+		if d.DescChange {
+			descCell = "~~" + markdown.Escape(d.Current.Description) + "~~ → " + descCell
+		}
+		change := "update"
+		switch {
+		case d.Type == diff.DiffTypeRename:
+			change = "rename from `" + markdown.Escape(d.Current.Name) + "`"
+		case diff.Blocked(d):
+			change = "differs (kept: `create-only`)"
+		case d.Desired.Policy == api.PolicyEnforce:
+			change = "update (enforced)"
+		case !force:
+			change = "differs (skipped without `--force`)"
+		}
+		return fmt.Sprintf("| ✏️ | %s | %s | %s | %s |\n", markdown.Escape(d.Name), colorCell, descCell, change)
+	case diff.DiffTypeExtra:
+		change := "delete"
+		if d.Excluded {
+			change = "excluded by a condition (kept)"
+		} else if diff.Blocked(d) {
+			change = "unmanaged (protected)"
+		} else if !deleteUnmanaged {
+			change = "unmanaged (kept)"
+		}
+		return markdownRow("⚠️", d.Name, markdown.Swatch(d.Current.Color), d.Current.Description, change)
+	case diff.DiffTypeDelete:
+		change := "delete"
+		if diff.Blocked(d) {
+			change = "marked for deletion (protected)"
+		}
+		return markdownRow("🗑️", d.Name, markdown.Swatch(d.Current.Color), d.Current.Description, change)
+	}
+	return ""
+}
+
+// FormatMarkdownResult formats the result of a sync operation as Markdown
+func FormatMarkdownResult(created, updated, deleted int) string {
+	return fmt.Sprintf("**Result:** %d created, %d updated, %d deleted\n\n", created, updated, deleted)
+}
+
+func markdownRow(icon, name, colorCell, description, change string) string {
+	return fmt.Sprintf("| %s | %s | %s | %s | %s |\n", icon, markdown.Escape(name), colorCell, markdown.Escape(description), change)
+}
@@ -4,67 +4,246 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/scttfrdmn/gh-label-sync/pkg/api"
 	"github.com/scttfrdmn/gh-label-sync/pkg/diff"
 )
 
 // FormatDiff formats a diff for display
-func FormatDiff(diffs []diff.LabelDiff, verbose bool) string {
+func FormatDiff(diffs []diff.LabelDiff, verbose bool, style Style) string {
 	var sb strings.Builder
 
 	sb.WriteString("Analyzing labels...\n")
 
 	for _, d := range diffs {
-		switch d.Type {
-		case diff.DiffTypeMatch:
-			if verbose {
-				sb.WriteString(fmt.Sprintf("  ✓ %s - matches\n", d.Name))
-			}
-		case diff.DiffTypeCreate:
-			sb.WriteString(fmt.Sprintf("  + %s - will create (color: %s)\n", d.Name, d.Desired.Color))
-		case diff.DiffTypeUpdate:
-			changes := []string{}
-			if d.ColorChange {
-				changes = append(changes, fmt.Sprintf("color: %s → %s", d.Current.Color, d.Desired.Color))
+		if d.Type == diff.DiffTypeMatch && !verbose {
+			continue
+		}
+		sb.WriteString("  " + FormatDiffLine(d, style) + "\n")
+		for _, line := range formatDetails(d, verbose, style) {
+			sb.WriteString("      " + line + "\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// FormatGroupedDiff formats a diff for display under a heading per label
+// prefix. Diffs are expected to be sorted with grouping, as by diff.Sort.
+func FormatGroupedDiff(diffs []diff.LabelDiff, verbose bool, style Style) string {
+	var sb strings.Builder
+
+	sb.WriteString("Analyzing labels...\n")
+
+	for _, g := range diff.Groups(diffs) {
+		var lines []string
+		shown := 0
+		for _, d := range g.Diffs {
+			if d.Type == diff.DiffTypeMatch && !verbose {
+				continue
 			}
-			if d.DescChange {
-				changes = append(changes, "description")
+			shown++
+			lines = append(lines, "    "+FormatDiffLine(d, style))
+			for _, line := range formatDetails(d, verbose, style) {
+				lines = append(lines, "        "+line)
 			}
-			sb.WriteString(fmt.Sprintf("  ~ %s - differs (%s)\n", d.Name, strings.Join(changes, ", ")))
-		case diff.DiffTypeExtra:
-			sb.WriteString(fmt.Sprintf("  ⚠ %s - exists but not in file\n", d.Name))
+		}
+		if shown == 0 {
+			continue
+		}
+
+		heading := g.Prefix
+		if heading == "" {
+			heading = "(no prefix)"
+		}
+		sb.WriteString(fmt.Sprintf("  %s (%d)\n", heading, shown))
+		for _, line := range lines {
+			sb.WriteString(line + "\n")
 		}
 	}
 
 	return sb.String()
 }
 
+// formatDetails returns the lines shown beneath an update: the description
+// change, or every field of the label in verbose mode
+func formatDetails(d diff.LabelDiff, verbose bool, style Style) []string {
+	if d.Type != diff.DiffTypeUpdate && d.Type != diff.DiffTypeRename {
+		return nil
+	}
+
+	var lines []string
+	if verbose {
+		if d.Type == diff.DiffTypeRename {
+			lines = append(lines, fmt.Sprintf("name:        %s → %s", d.Current.Name, d.Name))
+		} else {
+			lines = append(lines, fmt.Sprintf("name:        %s", d.Name))
+		}
+		if d.ColorChange {
+			lines = append(lines, fmt.Sprintf("color:       %s → %s", style.Swatch(d.Current.Color), style.Swatch(d.Desired.Color)))
+		} else {
+			lines = append(lines, fmt.Sprintf("color:       %s", style.Swatch(d.Desired.Color)))
+		}
+		if !d.DescChange {
+			lines = append(lines, fmt.Sprintf("description: %s", d.Desired.Description))
+		}
+		if d.Desired.Priority != nil {
+			lines = append(lines, fmt.Sprintf("priority:    %s", formatPriority(d.Desired.Priority)))
+		}
+		if d.Desired.Exclusive != nil {
+			lines = append(lines, fmt.Sprintf("exclusive:   %t", *d.Desired.Exclusive))
+		}
+	}
+
+	if d.DescChange {
+		lines = append(lines, FormatDescriptionDiff(d.Current.Description, d.Desired.Description, style)...)
+	}
+
+	return lines
+}
+
+// formatPriority formats an optional priority
+func formatPriority(p *int) string {
+	if p == nil {
+		return "none"
+	}
+	return fmt.Sprint(*p)
+}
+
+// FormatDescriptionDiff shows how a description changed. With color support
+// the words are highlighted inline; otherwise a unified diff is returned.
+func FormatDescriptionDiff(old, new string, style Style) []string {
+	if style.Mode == ColorNone {
+		return []string{
+			"description:",
+			"  - " + old,
+			"  + " + new,
+		}
+	}
+
+	var sb strings.Builder
+	for _, c := range diff.DiffWords(old, new) {
+		switch c.Op {
+		case diff.WordEqual:
+			sb.WriteString(c.Text)
+		case diff.WordDelete:
+			sb.WriteString(style.Deleted(c.Text))
+		case diff.WordInsert:
+			sb.WriteString(style.Inserted(c.Text))
+		}
+	}
+
+	return []string{"description: " + sb.String()}
+}
+
+// FormatDiffLine formats a single diff entry as one line
+func FormatDiffLine(d diff.LabelDiff, style Style) string {
+	switch d.Type {
+	case diff.DiffTypeMatch:
+		return fmt.Sprintf("%s %s - matches", style.Green("✓"), style.Badge(d.Name, d.Current.Color))
+	case diff.DiffTypeCreate:
+		return fmt.Sprintf("%s %s - will create (color: %s)", style.Green("+"), style.Badge(d.Name, d.Desired.Color), style.Swatch(d.Desired.Color))
+	case diff.DiffTypeUpdate, diff.DiffTypeRename:
+		changes := []string{}
+		if d.ColorChange {
+			changes = append(changes, fmt.Sprintf("color: %s → %s", style.Swatch(d.Current.Color), style.Swatch(d.Desired.Color)))
+		}
+		if d.DescChange {
+			changes = append(changes, "description")
+		}
+		if d.PriorityChange {
+			changes = append(changes, fmt.Sprintf("priority: %s → %s", formatPriority(d.Current.Priority), formatPriority(d.Desired.Priority)))
+		}
+		if d.ExclusiveChange {
+			changes = append(changes, fmt.Sprintf("exclusive: %t", *d.Desired.Exclusive))
+		}
+		if d.Type == diff.DiffTypeRename {
+			line := fmt.Sprintf("%s %s - will rename from %s", style.Yellow("~"), style.Badge(d.Name, d.Desired.Color), d.Current.Name)
+			if len(changes) > 0 {
+				line += " (" + strings.Join(changes, ", ") + ")"
+			}
+			return line
+		}
+		return fmt.Sprintf("%s %s - differs (%s)%s", style.Yellow("~"), style.Badge(d.Name, d.Desired.Color), strings.Join(changes, ", "), policyNote(d))
+	case diff.DiffTypeExtra:
+		if d.Excluded {
+			return fmt.Sprintf("%s %s - exists but excluded by a condition (kept)", style.Yellow("○"), style.Badge(d.Name, d.Current.Color))
+		}
+		if d.Current.IssueCount > 0 {
+			return fmt.Sprintf("%s %s - exists but not in file (used by %d issue(s))%s", style.Red("⚠"), style.Badge(d.Name, d.Current.Color), d.Current.IssueCount, policyNote(d))
+		}
+		return fmt.Sprintf("%s %s - exists but not in file%s", style.Red("⚠"), style.Badge(d.Name, d.Current.Color), policyNote(d))
+	case diff.DiffTypeDelete:
+		if diff.Blocked(d) {
+			return fmt.Sprintf("%s %s - marked for deletion%s", style.Red("⚠"), style.Badge(d.Name, d.Current.Color), policyNote(d))
+		}
+		if d.Current.IssueCount > 0 {
+			return fmt.Sprintf("%s %s - will delete (used by %d issue(s))", style.Red("-"), style.Badge(d.Name, d.Current.Color), d.Current.IssueCount)
+		}
+		return fmt.Sprintf("%s %s - will delete", style.Red("-"), style.Badge(d.Name, d.Current.Color))
+	}
+	return d.Name
+}
+
+// FormatSuggestion formats a suggested rename as one line
+func FormatSuggestion(s diff.RenameSuggestion, style Style) string {
+	return fmt.Sprintf("%s %s → %s (%s)", style.Yellow("?"), s.From, s.To, s.Reason())
+}
+
+// policyNote explains how a label's policy or protection affects a diff
+func policyNote(d diff.LabelDiff) string {
+	switch {
+	case d.Type == diff.DiffTypeUpdate && d.Desired.Policy == api.PolicyEnforce:
+		return " [enforced]"
+	case diff.Blocked(d) && d.Type == diff.DiffTypeUpdate:
+		return " [create-only, kept]"
+	case diff.Blocked(d):
+		return " [protected, kept]"
+	}
+	return ""
+}
+
 // FormatSummary formats a summary of changes
 func FormatSummary(diffs []diff.LabelDiff, force, deleteUnmanaged bool) string {
-	matches, creates, updates, extras := diff.Summary(diffs)
+	c := diff.Summary(diffs)
 
 	var sb strings.Builder
 	sb.WriteString("\nSummary:\n")
 
-	if matches > 0 {
-		sb.WriteString(fmt.Sprintf("  %d label(s) match\n", matches))
+	if c.Matches > 0 {
+		sb.WriteString(fmt.Sprintf("  %d label(s) match\n", c.Matches))
+	}
+	if c.Creates > 0 {
+		sb.WriteString(fmt.Sprintf("  %d label(s) to create\n", c.Creates))
 	}
-	if creates > 0 {
-		sb.WriteString(fmt.Sprintf("  %d label(s) to create\n", creates))
+	if c.Renames > 0 {
+		sb.WriteString(fmt.Sprintf("  %d label(s) to rename\n", c.Renames))
 	}
-	if updates > 0 {
+	if c.Enforced > 0 {
+		sb.WriteString(fmt.Sprintf("  %d enforced label(s) to update\n", c.Enforced))
+	}
+	if c.Updates > 0 {
 		if force {
-			sb.WriteString(fmt.Sprintf("  %d label(s) to update\n", updates))
+			sb.WriteString(fmt.Sprintf("  %d label(s) to update\n", c.Updates))
 		} else {
-			sb.WriteString(fmt.Sprintf("  %d label(s) differ (use --force to update)\n", updates))
+			sb.WriteString(fmt.Sprintf("  %d label(s) differ (use --force to update)\n", c.Updates))
 		}
 	}
-	if extras > 0 {
+	if c.Deletes > 0 {
+		sb.WriteString(fmt.Sprintf("  %d label(s) to delete\n", c.Deletes))
+	}
+	if c.Extras > 0 {
 		if deleteUnmanaged {
-			sb.WriteString(fmt.Sprintf("  %d unmanaged label(s) to delete\n", extras))
+			sb.WriteString(fmt.Sprintf("  %d unmanaged label(s) to delete\n", c.Extras))
 		} else {
-			sb.WriteString(fmt.Sprintf("  %d unmanaged label(s) (use --delete-unmanaged to remove)\n", extras))
+			sb.WriteString(fmt.Sprintf("  %d unmanaged label(s) (use --delete-unmanaged to remove)\n", c.Extras))
 		}
 	}
+	if c.Kept > 0 {
+		sb.WriteString(fmt.Sprintf("  %d label(s) kept by policy\n", c.Kept))
+	}
+	if c.Excluded > 0 {
+		sb.WriteString(fmt.Sprintf("  %d label(s) excluded by a condition (kept)\n", c.Excluded))
+	}
 
 	return sb.String()
 }
@@ -0,0 +1,104 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/scttfrdmn/gh-label-sync/pkg/diff"
+)
+
+// FormatPull formats the changes pulling from a repository would make to a
+// label file. The diffs compare the file (desired) with the repository
+// (current): labels that differ take the repository's values, unless they
+// are create-only, and labels only in the repository are added when addNew
+// is set. Labels the file defines for other repositories are not shown.
+func FormatPull(diffs []diff.LabelDiff, addNew bool, style Style) string {
+	var sb strings.Builder
+
+	for _, d := range diffs {
+		if d.Excluded {
+			continue
+		}
+		switch d.Type {
+		case diff.DiffTypeUpdate:
+			changes := []string{}
+			if d.ColorChange {
+				changes = append(changes, fmt.Sprintf("color: %s → %s", style.Swatch(d.Desired.Color), style.Swatch(d.Current.Color)))
+			}
+			if d.DescChange {
+				changes = append(changes, "description")
+			}
+			if d.PriorityChange {
+				changes = append(changes, fmt.Sprintf("priority: %s → %s", formatPriority(d.Desired.Priority), formatPriority(d.Current.Priority)))
+			}
+			if d.ExclusiveChange {
+				changes = append(changes, fmt.Sprintf("exclusive: %t", d.Current.Exclusive != nil && *d.Current.Exclusive))
+			}
+			if diff.Blocked(d) {
+				sb.WriteString(fmt.Sprintf("  %s %s - differs (%s) [create-only, kept]\n", style.Yellow("~"), style.Badge(d.Name, d.Desired.Color), strings.Join(changes, ", ")))
+				continue
+			}
+			sb.WriteString(fmt.Sprintf("  %s %s - will update (%s)\n", style.Yellow("~"), style.Badge(d.Name, d.Current.Color), strings.Join(changes, ", ")))
+			if d.DescChange {
+				for _, line := range FormatDescriptionDiff(d.Desired.Description, d.Current.Description, style) {
+					sb.WriteString("      " + line + "\n")
+				}
+			}
+		case diff.DiffTypeExtra:
+			if addNew {
+				sb.WriteString(fmt.Sprintf("  %s %s - will add (color: %s)\n", style.Green("+"), style.Badge(d.Name, d.Current.Color), style.Swatch(d.Current.Color)))
+			} else {
+				sb.WriteString(fmt.Sprintf("  %s %s - only in repository\n", style.Red("⚠"), style.Badge(d.Name, d.Current.Color)))
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+// FormatPullSummary formats a summary of the changes pull would make. Every
+// label that differs is counted once: as an update, or as kept if it is
+// create-only.
+func FormatPullSummary(diffs []diff.LabelDiff, addNew bool) string {
+	var matches, updates, kept, extras, fileOnly int
+	for _, d := range diffs {
+		switch {
+		case d.Excluded:
+		case d.Type == diff.DiffTypeMatch:
+			matches++
+		case d.Type == diff.DiffTypeUpdate && diff.Blocked(d):
+			kept++
+		case d.Type == diff.DiffTypeUpdate:
+			updates++
+		case d.Type == diff.DiffTypeExtra:
+			extras++
+		case d.Type == diff.DiffTypeCreate:
+			fileOnly++
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\nSummary:\n")
+
+	if matches > 0 {
+		sb.WriteString(fmt.Sprintf("  %d label(s) match\n", matches))
+	}
+	if updates > 0 {
+		sb.WriteString(fmt.Sprintf("  %d label(s) to update from the repository\n", updates))
+	}
+	if kept > 0 {
+		sb.WriteString(fmt.Sprintf("  %d create-only label(s) differ (kept)\n", kept))
+	}
+	if extras > 0 {
+		if addNew {
+			sb.WriteString(fmt.Sprintf("  %d label(s) to add from the repository\n", extras))
+		} else {
+			sb.WriteString(fmt.Sprintf("  %d label(s) only in the repository (use --add-new to add)\n", extras))
+		}
+	}
+	if fileOnly > 0 {
+		sb.WriteString(fmt.Sprintf("  %d label(s) only in the file (kept)\n", fileOnly))
+	}
+
+	return sb.String()
+}
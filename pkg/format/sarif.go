@@ -0,0 +1,159 @@
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	toolName     = "gh-label-sync"
+	toolURI      = "https://github.com/scttfrdmn/gh-label-sync"
+)
+
+// SARIFRule describes a rule that results refer to
+type SARIFRule struct {
+	ID          string
+	Description string
+}
+
+// SARIFResult is a problem found in a label file. Level is error, warning,
+// or note. A zero Line places the result at the top of the file.
+type SARIFResult struct {
+	RuleID  string
+	Level   string
+	Message string
+	File    string
+	Line    int
+	Column  int
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string                `json:"name"`
+	InformationURI string                `json:"informationUri"`
+	Rules          []sarifRuleDescriptor `json:"rules"`
+}
+
+type sarifRuleDescriptor struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// WriteSARIF writes results as a SARIF 2.1.0 log, which GitHub code scanning
+// shows inline on the files. Rules referenced by results but missing from
+// rules are added with their ID as the description.
+func WriteSARIF(w io.Writer, rules []SARIFRule, results []SARIFResult) error {
+	driver := sarifDriver{Name: toolName, InformationURI: toolURI, Rules: []sarifRuleDescriptor{}}
+	index := make(map[string]int)
+	addRule := func(id, description string) {
+		if _, ok := index[id]; ok {
+			return
+		}
+		index[id] = len(driver.Rules)
+		driver.Rules = append(driver.Rules, sarifRuleDescriptor{ID: id, ShortDescription: sarifMessage{Text: description}})
+	}
+	for _, r := range rules {
+		addRule(r.ID, r.Description)
+	}
+
+	run := sarifRun{Results: []sarifResult{}}
+	for _, r := range results {
+		addRule(r.RuleID, r.RuleID)
+
+		result := sarifResult{
+			RuleID:    r.RuleID,
+			RuleIndex: index[r.RuleID],
+			Level:     r.Level,
+			Message:   sarifMessage{Text: r.Message},
+		}
+		if r.File != "" && r.File != "-" {
+			region := sarifRegion{StartLine: r.Line, StartColumn: r.Column}
+			if region.StartLine == 0 {
+				region = sarifRegion{StartLine: 1}
+			}
+			result.Locations = []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: artifactLocation(r.File),
+				Region:           region,
+			}}}
+		}
+		run.Results = append(run.Results, result)
+	}
+	run.Tool = sarifTool{Driver: driver}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}}); err != nil {
+		return fmt.Errorf("failed to write SARIF: %w", err)
+	}
+	return nil
+}
+
+// artifactLocation locates a file relative to the root of the repository
+// checkout that contains it, which is what code scanning matches results
+// against. Files outside a checkout keep the path as given.
+func artifactLocation(file string) sarifArtifactLocation {
+	if abs, err := filepath.Abs(file); err == nil {
+		for dir := filepath.Dir(abs); ; dir = filepath.Dir(dir) {
+			if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+				if rel, err := filepath.Rel(dir, abs); err == nil {
+					return sarifArtifactLocation{URI: filepath.ToSlash(rel), URIBaseID: "%SRCROOT%"}
+				}
+				break
+			}
+			if filepath.Dir(dir) == dir {
+				break
+			}
+		}
+	}
+	return sarifArtifactLocation{URI: filepath.ToSlash(filepath.Clean(file))}
+}
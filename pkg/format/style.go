@@ -0,0 +1,206 @@
+package format
+
+import (
+	"fmt"
+
+	"github.com/cli/go-gh/v2/pkg/term"
+	"github.com/scttfrdmn/gh-label-sync/pkg/color"
+)
+
+// ColorMode describes how much color the output terminal supports
+type ColorMode int
+
+const (
+	ColorNone ColorMode = iota
+	Color16
+	Color256
+	ColorTrue
+)
+
+// Style renders text for a terminal with a given color capability. The zero
+// value produces plain text.
+type Style struct {
+	Mode ColorMode
+}
+
+// Plain is a style that never emits escape sequences
+var Plain = Style{Mode: ColorNone}
+
+// DetectStyle picks a style for stdout based on whether it is a terminal,
+// the advertised color support, and NO_COLOR/CLICOLOR settings
+func DetectStyle() Style {
+	t := term.FromEnv()
+
+	switch {
+	case !t.IsColorEnabled():
+		return Style{Mode: ColorNone}
+	case t.IsTrueColorSupported():
+		return Style{Mode: ColorTrue}
+	case t.Is256ColorSupported():
+		return Style{Mode: Color256}
+	default:
+		return Style{Mode: Color16}
+	}
+}
+
+// Badge renders a label name the way GitHub does: on its label color with
+// contrasting text. Without color support the name is returned unchanged.
+func (s Style) Badge(name, hex string) string {
+	bg, err := color.Parse(hex)
+	if s.Mode == ColorNone || err != nil {
+		return name
+	}
+
+	return s.bg(bg) + s.fg(bg.TextColor()) + " " + name + " \x1b[0m"
+}
+
+// Swatch renders a small block of the given color followed by its hex code
+func (s Style) Swatch(hex string) string {
+	c, err := color.Parse(hex)
+	if s.Mode == ColorNone || err != nil {
+		return hex
+	}
+
+	return s.bg(c) + "  \x1b[0m " + hex
+}
+
+// Green renders text in green
+func (s Style) Green(text string) string {
+	return s.ansi("32", text)
+}
+
+// Yellow renders text in yellow
+func (s Style) Yellow(text string) string {
+	return s.ansi("33", text)
+}
+
+// Red renders text in red
+func (s Style) Red(text string) string {
+	return s.ansi("31", text)
+}
+
+// Deleted renders removed text in red with a strikethrough
+func (s Style) Deleted(text string) string {
+	return s.ansi("31;9", text)
+}
+
+// Inserted renders added text in green with an underline
+func (s Style) Inserted(text string) string {
+	return s.ansi("32;4", text)
+}
+
+func (s Style) ansi(code, text string) string {
+	if s.Mode == ColorNone {
+		return text
+	}
+	return "\x1b[" + code + "m" + text + "\x1b[0m"
+}
+
+// bg returns the escape sequence selecting c as the background color
+func (s Style) bg(c color.RGB) string {
+	switch s.Mode {
+	case ColorTrue:
+		return fmt.Sprintf("\x1b[48;2;%d;%d;%dm", c.R, c.G, c.B)
+	case Color256:
+		return fmt.Sprintf("\x1b[48;5;%dm", to256(c))
+	case Color16:
+		return fmt.Sprintf("\x1b[%dm", to16(c)+10)
+	}
+	return ""
+}
+
+// fg returns the escape sequence selecting c as the foreground color
+func (s Style) fg(c color.RGB) string {
+	switch s.Mode {
+	case ColorTrue:
+		return fmt.Sprintf("\x1b[38;2;%d;%d;%dm", c.R, c.G, c.B)
+	case Color256:
+		return fmt.Sprintf("\x1b[38;5;%dm", to256(c))
+	case Color16:
+		return fmt.Sprintf("\x1b[%dm", to16(c))
+	}
+	return ""
+}
+
+// cubeLevels are the channel values of the 6x6x6 cube in the 256-color palette
+var cubeLevels = [6]int{0, 95, 135, 175, 215, 255}
+
+// to256 maps c to the closest entry of the xterm 256-color palette, choosing
+// between the color cube and the grayscale ramp
+func to256(c color.RGB) int {
+	nearestLevel := func(v uint8) int {
+		best := 0
+		for i, l := range cubeLevels {
+			if abs(int(v)-l) < abs(int(v)-cubeLevels[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+
+	r, g, b := nearestLevel(c.R), nearestLevel(c.G), nearestLevel(c.B)
+	cube := color.RGB{R: uint8(cubeLevels[r]), G: uint8(cubeLevels[g]), B: uint8(cubeLevels[b])}
+
+	avg := (int(c.R) + int(c.G) + int(c.B)) / 3
+	grayIdx := (avg - 8) / 10
+	if grayIdx < 0 {
+		grayIdx = 0
+	} else if grayIdx > 23 {
+		grayIdx = 23
+	}
+	level := uint8(8 + grayIdx*10)
+	gray := color.RGB{R: level, G: level, B: level}
+
+	if distance(c, gray) < distance(c, cube) {
+		return 232 + grayIdx
+	}
+	return 16 + 36*r + 6*g + b
+}
+
+// ansi16 holds approximate RGB values of the standard foreground colors 30-37
+// and their bright variants 90-97
+var ansi16 = []struct {
+	code int
+	rgb  color.RGB
+}{
+	{30, color.RGB{R: 0, G: 0, B: 0}},
+	{31, color.RGB{R: 205, G: 0, B: 0}},
+	{32, color.RGB{R: 0, G: 205, B: 0}},
+	{33, color.RGB{R: 205, G: 205, B: 0}},
+	{34, color.RGB{R: 0, G: 0, B: 238}},
+	{35, color.RGB{R: 205, G: 0, B: 205}},
+	{36, color.RGB{R: 0, G: 205, B: 205}},
+	{37, color.RGB{R: 229, G: 229, B: 229}},
+	{90, color.RGB{R: 127, G: 127, B: 127}},
+	{91, color.RGB{R: 255, G: 0, B: 0}},
+	{92, color.RGB{R: 0, G: 255, B: 0}},
+	{93, color.RGB{R: 255, G: 255, B: 0}},
+	{94, color.RGB{R: 92, G: 92, B: 255}},
+	{95, color.RGB{R: 255, G: 0, B: 255}},
+	{96, color.RGB{R: 0, G: 255, B: 255}},
+	{97, color.RGB{R: 255, G: 255, B: 255}},
+}
+
+// to16 maps c to the foreground code of the closest basic ANSI color
+func to16(c color.RGB) int {
+	best := ansi16[0]
+	for _, a := range ansi16[1:] {
+		if distance(c, a.rgb) < distance(c, best.rgb) {
+			best = a
+		}
+	}
+	return best.code
+}
+
+// distance returns the squared euclidean distance between two colors
+func distance(a, b color.RGB) int {
+	dr, dg, db := int(a.R)-int(b.R), int(a.G)-int(b.G), int(a.B)-int(b.B)
+	return dr*dr + dg*dg + db*db
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,268 @@
+package gitea
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/scttfrdmn/gh-label-sync/pkg/api"
+)
+
+const (
+	// orgPrefix marks a --repo value as an organization rather than a repo
+	orgPrefix = "org:"
+
+	pageSize = 50
+)
+
+// Client is a label store backed by the Gitea API, which Forgejo also
+// implements. It manages either a repository's labels or, for "org:NAME", an
+// organization's labels.
+type Client struct {
+	http       *api.JSONClient
+	labelsPath string
+
+	// repoPath is the repository's API path, empty for an organization
+	repoPath string
+	org      string
+
+	// labelIDs maps label names to IDs, which the API addresses labels by
+	labelIDs map[string]int64
+}
+
+// Options configures how a client connects to Gitea or Forgejo
+type Options struct {
+	// Hostname defaults to $GITEA_HOST, then $FORGEJO_HOST
+	Hostname string
+
+	// Token defaults to $GITEA_TOKEN, then $FORGEJO_TOKEN
+	Token string
+
+	// BaseURL overrides the API root, e.g. to talk to a fake server
+	BaseURL string
+
+	// Transport overrides the HTTP transport
+	Transport http.RoundTripper
+}
+
+type label struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	Description string `json:"description"`
+	Exclusive   bool   `json:"exclusive"`
+}
+
+type labelInput struct {
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	Description string `json:"description"`
+	Exclusive   *bool  `json:"exclusive,omitempty"`
+}
+
+// NewClient creates a client for a repository (owner/repo) or an
+// organization (org:name)
+func NewClient(path string, opts Options) (*Client, error) {
+	if path == "" {
+		return nil, fmt.Errorf("Gitea repository required (use --repo owner/repo or --repo org:name)")
+	}
+
+	token := firstNonEmpty(opts.Token, os.Getenv("GITEA_TOKEN"), os.Getenv("FORGEJO_TOKEN"))
+	if token == "" {
+		return nil, fmt.Errorf("Gitea token required (set GITEA_TOKEN or FORGEJO_TOKEN)")
+	}
+
+	baseURL := opts.BaseURL
+	if baseURL == "" {
+		host := firstNonEmpty(opts.Hostname, os.Getenv("GITEA_HOST"), os.Getenv("FORGEJO_HOST"))
+		if host == "" {
+			return nil, fmt.Errorf("Gitea host required (use --hostname or set GITEA_HOST)")
+		}
+		baseURL = "https://" + host + "/api/v1"
+	}
+
+	var labelsPath, repoPath string
+	org, isOrg := strings.CutPrefix(path, orgPrefix)
+	if isOrg {
+		labelsPath = "orgs/" + url.PathEscape(org) + "/labels"
+	} else {
+		owner, repo, ok := strings.Cut(path, "/")
+		if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+			return nil, fmt.Errorf("invalid repository format: expected OWNER/REPO or org:NAME, got %q", path)
+		}
+		repoPath = "repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo)
+		labelsPath = repoPath + "/labels"
+	}
+
+	return &Client{
+		http:       api.NewJSONClient(baseURL, http.Header{"Authorization": {"token " + token}}, opts.Transport),
+		labelsPath: labelsPath,
+		repoPath:   repoPath,
+		org:        org,
+		labelIDs:   make(map[string]int64),
+	}, nil
+}
+
+// SupportsField reports which optional label fields Gitea supports
+func (c *Client) SupportsField(field api.Field) bool {
+	return field == api.FieldExclusive
+}
+
+// Repository fetches the repository's owner, name, topics, main language,
+// and visibility. An organization has its name as the owner and no name.
+func (c *Client) Repository() (*api.Repository, error) {
+	if c.repoPath == "" {
+		return &api.Repository{Owner: c.org}, nil
+	}
+
+	var repo struct {
+		Name  string `json:"name"`
+		Owner struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+		Topics   []string `json:"topics"`
+		Private  bool     `json:"private"`
+		Internal bool     `json:"internal"`
+	}
+	if err := c.http.Do(http.MethodGet, c.repoPath, nil, &repo); err != nil {
+		return nil, fmt.Errorf("failed to get repository: %w", err)
+	}
+
+	// Languages are reported as bytes of code
+	var languages map[string]float64
+	if err := c.http.Do(http.MethodGet, c.repoPath+"/languages", nil, &languages); err != nil {
+		return nil, fmt.Errorf("failed to get repository languages: %w", err)
+	}
+
+	visibility := "public"
+	if repo.Internal {
+		visibility = "internal"
+	} else if repo.Private {
+		visibility = "private"
+	}
+
+	return &api.Repository{
+		Owner:      repo.Owner.Login,
+		Name:       repo.Name,
+		Topics:     repo.Topics,
+		Language:   api.PrimaryLanguage(languages),
+		Visibility: visibility,
+	}, nil
+}
+
+// ListLabels lists all labels in the repository or organization
+func (c *Client) ListLabels() ([]api.Label, error) {
+	var labels []api.Label
+
+	for page := 1; ; page++ {
+		var batch []label
+		path := fmt.Sprintf("%s?page=%d&limit=%d", c.labelsPath, page, pageSize)
+		if err := c.http.Do(http.MethodGet, path, nil, &batch); err != nil {
+			return nil, fmt.Errorf("failed to list labels: %w", err)
+		}
+
+		for _, l := range batch {
+			c.labelIDs[l.Name] = l.ID
+			labels = append(labels, l.toLabel())
+		}
+
+		if len(batch) < pageSize {
+			break
+		}
+	}
+
+	return labels, nil
+}
+
+// CreateLabel creates a new label
+func (c *Client) CreateLabel(input api.LabelInput) (*api.Label, error) {
+	var created label
+	if err := c.http.Do(http.MethodPost, c.labelsPath, newLabelInput(input), &created); err != nil {
+		return nil, fmt.Errorf("failed to create label: %w", err)
+	}
+
+	c.labelIDs[created.Name] = created.ID
+	l := created.toLabel()
+	return &l, nil
+}
+
+// UpdateLabel updates an existing label
+func (c *Client) UpdateLabel(name string, input api.LabelInput) (*api.Label, error) {
+	id, err := c.labelID(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update label: %w", err)
+	}
+
+	var updated label
+	if err := c.http.Do(http.MethodPatch, fmt.Sprintf("%s/%d", c.labelsPath, id), newLabelInput(input), &updated); err != nil {
+		return nil, fmt.Errorf("failed to update label: %w", err)
+	}
+
+	delete(c.labelIDs, name)
+	c.labelIDs[updated.Name] = updated.ID
+	l := updated.toLabel()
+	return &l, nil
+}
+
+// DeleteLabel deletes a label
+func (c *Client) DeleteLabel(name string) error {
+	id, err := c.labelID(name)
+	if err != nil {
+		return fmt.Errorf("failed to delete label: %w", err)
+	}
+
+	if err := c.http.Do(http.MethodDelete, fmt.Sprintf("%s/%d", c.labelsPath, id), nil, nil); err != nil {
+		return fmt.Errorf("failed to delete label: %w", err)
+	}
+
+	delete(c.labelIDs, name)
+	return nil
+}
+
+// labelID looks up a label's ID, listing labels if it is not cached
+func (c *Client) labelID(name string) (int64, error) {
+	if id, ok := c.labelIDs[name]; ok {
+		return id, nil
+	}
+	if _, err := c.ListLabels(); err != nil {
+		return 0, err
+	}
+	if id, ok := c.labelIDs[name]; ok {
+		return id, nil
+	}
+	return 0, fmt.Errorf("label %q not found", name)
+}
+
+func newLabelInput(input api.LabelInput) labelInput {
+	return labelInput{
+		Name:        input.Name,
+		Color:       "#" + api.NormalizeColor(input.Color),
+		Description: input.Description,
+		Exclusive:   input.Exclusive,
+	}
+}
+
+// toLabel converts an API label. Exclusive is only set for exclusive labels,
+// so exports do not write "exclusive: false" for every label.
+func (l label) toLabel() api.Label {
+	converted := api.Label{
+		Name:        l.Name,
+		Color:       api.NormalizeColor(l.Color),
+		Description: l.Description,
+	}
+	if l.Exclusive {
+		converted.Exclusive = &l.Exclusive
+	}
+	return converted
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,121 @@
+package gitea
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/scttfrdmn/gh-label-sync/pkg/api"
+)
+
+// newFakeGitea starts a stand-in for the Gitea API and returns a client for
+// path that talks to it
+func newFakeGitea(t *testing.T, path string, handle http.HandlerFunc) *Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "token test-token" {
+			t.Errorf("Authorization = %q, want the test token", got)
+		}
+		handle(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(path, Options{Token: "test-token", BaseURL: server.URL + "/api/v1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func writeJSON(t *testing.T, w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestListLabels(t *testing.T) {
+	var pages []string
+	client := newFakeGitea(t, "owner/repo", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repos/owner/repo/labels" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+
+		batch := []map[string]any{}
+		if page == "1" {
+			for i := range pageSize {
+				batch = append(batch, map[string]any{"id": i, "name": fmt.Sprintf("label-%d", i), "color": "#D73A4A", "exclusive": i == 0})
+			}
+		}
+		writeJSON(t, w, http.StatusOK, batch)
+	})
+
+	labels, err := client.ListLabels()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(labels) != pageSize || strings.Join(pages, ",") != "1,2" {
+		t.Errorf("got %d labels from pages %v, want %d from 1,2", len(labels), pages, pageSize)
+	}
+	if labels[0].Color != "d73a4a" {
+		t.Errorf("color = %q, want it lowercased without #", labels[0].Color)
+	}
+	if labels[0].Exclusive == nil || !*labels[0].Exclusive {
+		t.Errorf("label-0 exclusive = %v, want true", labels[0].Exclusive)
+	}
+	if labels[1].Exclusive != nil {
+		t.Errorf("label-1 exclusive = %v, want unset", *labels[1].Exclusive)
+	}
+}
+
+func TestChangesUseLabelIDs(t *testing.T) {
+	var requests []string
+	client := newFakeGitea(t, "org:acme", func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(t, w, http.StatusOK, []map[string]any{{"id": 7, "name": "bug", "color": "#d73a4a"}})
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			writeJSON(t, w, http.StatusOK, map[string]any{"id": 7, "name": "type: bug", "color": "#d73a4a"})
+		}
+	})
+
+	if _, err := client.UpdateLabel("bug", api.LabelInput{Name: "type: bug", Color: "d73a4a"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.DeleteLabel("type: bug"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.DeleteLabel("missing"); err == nil || !strings.Contains(err.Error(), `label "missing" not found`) {
+		t.Errorf("err = %v, want label not found", err)
+	}
+
+	want := []string{
+		"GET /api/v1/orgs/acme/labels",
+		"PATCH /api/v1/orgs/acme/labels/7",
+		"DELETE /api/v1/orgs/acme/labels/7",
+		"GET /api/v1/orgs/acme/labels",
+	}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("requests:\n%s\nwant:\n%s", strings.Join(requests, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestError(t *testing.T) {
+	client := newFakeGitea(t, "owner/repo", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, http.StatusUnprocessableEntity, map[string]any{"message": "label already exists"})
+	})
+
+	_, err := client.CreateLabel(api.LabelInput{Name: "bug", Color: "d73a4a"})
+	if err == nil || err.Error() != "failed to create label: HTTP 422: label already exists" {
+		t.Errorf("err = %v", err)
+	}
+}
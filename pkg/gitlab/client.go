@@ -0,0 +1,220 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/scttfrdmn/gh-label-sync/pkg/api"
+)
+
+const (
+	defaultHost = "gitlab.com"
+
+	// groupPrefix marks a --repo value as a group rather than a project
+	groupPrefix = "group:"
+)
+
+// Client is a label store backed by the GitLab REST API. It manages either a
+// project's labels or, for "group:PATH", a group's labels.
+type Client struct {
+	http       *api.JSONClient
+	labelsPath string
+
+	// path is the project path, or the group path for group labels
+	path  string
+	group bool
+}
+
+// Options configures how a client connects to GitLab
+type Options struct {
+	// Hostname defaults to $GITLAB_HOST, then gitlab.com
+	Hostname string
+
+	// Token defaults to $GITLAB_TOKEN
+	Token string
+
+	// BaseURL overrides the API root, e.g. to talk to a fake server
+	BaseURL string
+
+	// Transport overrides the HTTP transport
+	Transport http.RoundTripper
+}
+
+type label struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	Description string `json:"description"`
+	Priority    *int   `json:"priority"`
+}
+
+type labelInput struct {
+	Name        string  `json:"name,omitempty"`
+	NewName     string  `json:"new_name,omitempty"`
+	Color       string  `json:"color"`
+	Description *string `json:"description,omitempty"`
+	Priority    *int    `json:"priority,omitempty"`
+}
+
+// NewClient creates a client for a project path (group/project) or a group
+// (group:group/subgroup)
+func NewClient(path string, opts Options) (*Client, error) {
+	if path == "" {
+		return nil, fmt.Errorf("GitLab project required (use --repo group/project or --repo group:path)")
+	}
+
+	token := opts.Token
+	if token == "" {
+		token = os.Getenv("GITLAB_TOKEN")
+	}
+	if token == "" {
+		return nil, fmt.Errorf("GitLab token required (set GITLAB_TOKEN)")
+	}
+
+	baseURL := opts.BaseURL
+	if baseURL == "" {
+		host := opts.Hostname
+		if host == "" {
+			host = os.Getenv("GITLAB_HOST")
+		}
+		if host == "" {
+			host = defaultHost
+		}
+		baseURL = "https://" + host + "/api/v4"
+	}
+
+	labelsPath := "projects/" + url.PathEscape(path) + "/labels"
+	group, isGroup := strings.CutPrefix(path, groupPrefix)
+	if isGroup {
+		path = group
+		labelsPath = "groups/" + url.PathEscape(group) + "/labels"
+	}
+
+	return &Client{
+		http:       api.NewJSONClient(baseURL, http.Header{"Private-Token": {token}}, opts.Transport),
+		labelsPath: labelsPath,
+		path:       path,
+		group:      isGroup,
+	}, nil
+}
+
+// SupportsField reports which optional label fields GitLab supports
+func (c *Client) SupportsField(field api.Field) bool {
+	return field == api.FieldPriority
+}
+
+// Repository fetches the project's namespace, path, topics, main language,
+// and visibility. A group has its path as the owner and no name.
+func (c *Client) Repository() (*api.Repository, error) {
+	if c.group {
+		return &api.Repository{Owner: c.path}, nil
+	}
+
+	var project struct {
+		Path      string `json:"path"`
+		Namespace struct {
+			FullPath string `json:"full_path"`
+		} `json:"namespace"`
+		Topics     []string `json:"topics"`
+		Visibility string   `json:"visibility"`
+	}
+	projectPath := "projects/" + url.PathEscape(c.path)
+	if err := c.http.Do(http.MethodGet, projectPath, nil, &project); err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	// Languages are reported as percentages of the code
+	var languages map[string]float64
+	if err := c.http.Do(http.MethodGet, projectPath+"/languages", nil, &languages); err != nil {
+		return nil, fmt.Errorf("failed to get project languages: %w", err)
+	}
+
+	return &api.Repository{
+		Owner:      project.Namespace.FullPath,
+		Name:       project.Path,
+		Topics:     project.Topics,
+		Language:   api.PrimaryLanguage(languages),
+		Visibility: project.Visibility,
+	}, nil
+}
+
+// ListLabels lists all labels defined directly on the project or group
+func (c *Client) ListLabels() ([]api.Label, error) {
+	var labels []api.Label
+
+	for page := 1; ; page++ {
+		var batch []label
+		path := fmt.Sprintf("%s?per_page=100&page=%d&include_ancestor_groups=false", c.labelsPath, page)
+		if err := c.http.Do(http.MethodGet, path, nil, &batch); err != nil {
+			return nil, fmt.Errorf("failed to list labels: %w", err)
+		}
+
+		for _, l := range batch {
+			labels = append(labels, l.toLabel())
+		}
+
+		if len(batch) < 100 {
+			break
+		}
+	}
+
+	return labels, nil
+}
+
+// CreateLabel creates a new label
+func (c *Client) CreateLabel(input api.LabelInput) (*api.Label, error) {
+	body := labelInput{
+		Name:        input.Name,
+		Color:       "#" + api.NormalizeColor(input.Color),
+		Description: &input.Description,
+		Priority:    input.Priority,
+	}
+
+	var created label
+	if err := c.http.Do(http.MethodPost, c.labelsPath, body, &created); err != nil {
+		return nil, fmt.Errorf("failed to create label: %w", err)
+	}
+
+	l := created.toLabel()
+	return &l, nil
+}
+
+// UpdateLabel updates an existing label
+func (c *Client) UpdateLabel(name string, input api.LabelInput) (*api.Label, error) {
+	body := labelInput{
+		Color:       "#" + api.NormalizeColor(input.Color),
+		Description: &input.Description,
+		Priority:    input.Priority,
+	}
+	if input.Name != name {
+		body.NewName = input.Name
+	}
+
+	var updated label
+	if err := c.http.Do(http.MethodPut, c.labelsPath+"/"+url.PathEscape(name), body, &updated); err != nil {
+		return nil, fmt.Errorf("failed to update label: %w", err)
+	}
+
+	l := updated.toLabel()
+	return &l, nil
+}
+
+// DeleteLabel deletes a label
+func (c *Client) DeleteLabel(name string) error {
+	if err := c.http.Do(http.MethodDelete, c.labelsPath+"/"+url.PathEscape(name), nil, nil); err != nil {
+		return fmt.Errorf("failed to delete label: %w", err)
+	}
+	return nil
+}
+
+func (l label) toLabel() api.Label {
+	return api.Label{
+		Name:        l.Name,
+		Color:       api.NormalizeColor(l.Color),
+		Description: l.Description,
+		Priority:    l.Priority,
+	}
+}
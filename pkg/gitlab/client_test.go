@@ -0,0 +1,235 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/scttfrdmn/gh-label-sync/pkg/api"
+)
+
+// newFakeGitLab starts a stand-in for the GitLab API and returns a client
+// for path that talks to it
+func newFakeGitLab(t *testing.T, path string, handle http.HandlerFunc) *Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("PRIVATE-TOKEN"); got != "test-token" {
+			t.Errorf("PRIVATE-TOKEN = %q, want the test token", got)
+		}
+		handle(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(path, Options{Token: "test-token", BaseURL: server.URL + "/api/v4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func writeJSON(t *testing.T, w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestListLabelsPaginates(t *testing.T) {
+	var pages []string
+	client := newFakeGitLab(t, "group/project", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/api/v4/projects/group%2Fproject/labels" {
+			t.Errorf("path = %s", r.URL.EscapedPath())
+		}
+		if got := r.URL.Query().Get("include_ancestor_groups"); got != "false" {
+			t.Errorf("include_ancestor_groups = %q, want false", got)
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		pages = append(pages, r.URL.Query().Get("page"))
+
+		// Two full pages and a partial one
+		n := 100
+		if page == 3 {
+			n = 5
+		}
+		batch := make([]map[string]any, n)
+		for i := range batch {
+			batch[i] = map[string]any{"id": page*1000 + i, "name": fmt.Sprintf("label-%d-%d", page, i), "color": "#D73A4A"}
+		}
+		writeJSON(t, w, http.StatusOK, batch)
+	})
+
+	labels, err := client.ListLabels()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(labels) != 205 {
+		t.Errorf("got %d labels, want 205", len(labels))
+	}
+	if strings.Join(pages, ",") != "1,2,3" {
+		t.Errorf("pages = %v, want 1,2,3", pages)
+	}
+	if labels[0].Color != "d73a4a" {
+		t.Errorf("color = %q, want it lowercased without #", labels[0].Color)
+	}
+}
+
+func TestPriority(t *testing.T) {
+	var bodies []map[string]any
+	client := newFakeGitLab(t, "group/project", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(t, w, http.StatusOK, []map[string]any{
+				{"id": 1, "name": "p1", "color": "#ff0000", "priority": 1},
+				{"id": 2, "name": "p-none", "color": "#00ff00", "priority": nil},
+			})
+		default:
+			var body map[string]any
+			data, _ := io.ReadAll(r.Body)
+			if err := json.Unmarshal(data, &body); err != nil {
+				t.Fatal(err)
+			}
+			bodies = append(bodies, body)
+			writeJSON(t, w, http.StatusOK, map[string]any{"id": 3, "name": "p2", "color": "#0000ff", "priority": 2})
+		}
+	})
+
+	labels, err := client.ListLabels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if labels[0].Priority == nil || *labels[0].Priority != 1 {
+		t.Errorf("p1 priority = %v, want 1", labels[0].Priority)
+	}
+	if labels[1].Priority != nil {
+		t.Errorf("p-none priority = %v, want unset", *labels[1].Priority)
+	}
+
+	priority := 2
+	created, err := client.CreateLabel(api.LabelInput{Name: "p2", Color: "0000ff", Priority: &priority})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Priority == nil || *created.Priority != 2 {
+		t.Errorf("created priority = %v, want 2", created.Priority)
+	}
+	if _, err := client.UpdateLabel("p2", api.LabelInput{Name: "p2", Color: "0000ff"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if bodies[0]["priority"] != float64(2) || bodies[0]["color"] != "#0000ff" {
+		t.Errorf("create body = %v, want priority 2 and a # color", bodies[0])
+	}
+	if _, ok := bodies[1]["priority"]; ok {
+		t.Errorf("update body = %v, want an unset priority left out", bodies[1])
+	}
+}
+
+func TestGroupLabels(t *testing.T) {
+	var requests []string
+	client := newFakeGitLab(t, "group:parent/child", func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.EscapedPath())
+		if r.Method == http.MethodGet {
+			writeJSON(t, w, http.StatusOK, []map[string]any{{"id": 1, "name": "bug", "color": "#d73a4a"}})
+			return
+		}
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeJSON(t, w, http.StatusOK, map[string]any{"id": 1, "name": "type: bug", "color": "#d73a4a"})
+	})
+
+	if _, err := client.ListLabels(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.UpdateLabel("bug", api.LabelInput{Name: "type: bug", Color: "d73a4a"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.DeleteLabel("type: bug"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"GET /api/v4/groups/parent%2Fchild/labels",
+		"PUT /api/v4/groups/parent%2Fchild/labels/bug",
+		"DELETE /api/v4/groups/parent%2Fchild/labels/type:%20bug",
+	}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("requests:\n%s\nwant:\n%s", strings.Join(requests, "\n"), strings.Join(want, "\n"))
+	}
+
+	repo, err := client.Repository()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.Owner != "parent/child" || repo.Name != "" {
+		t.Errorf("repository = %+v, want the group as owner", repo)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   any
+		want   string
+	}{
+		{"message string", http.StatusConflict, map[string]any{"message": "Label already exists"}, "HTTP 409: Label already exists"},
+		{"message map", http.StatusBadRequest, map[string]any{"message": map[string]any{"color": []string{"is invalid"}}}, "HTTP 400: map[color:[is invalid]]"},
+		{"error field", http.StatusUnauthorized, map[string]any{"error": "invalid_token"}, "HTTP 401: invalid_token"},
+		{"no body", http.StatusInternalServerError, nil, "HTTP 500"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeGitLab(t, "group/project", func(w http.ResponseWriter, r *http.Request) {
+				if tt.body == nil {
+					w.WriteHeader(tt.status)
+					return
+				}
+				writeJSON(t, w, tt.status, tt.body)
+			})
+
+			_, err := client.CreateLabel(api.LabelInput{Name: "bug", Color: "d73a4a"})
+			if err == nil || err.Error() != "failed to create label: "+tt.want {
+				t.Errorf("err = %v, want %q", err, "failed to create label: "+tt.want)
+			}
+		})
+	}
+}
+
+func TestNewClientHost(t *testing.T) {
+	t.Setenv("GITLAB_HOST", "")
+	t.Setenv("GITLAB_TOKEN", "env-token")
+
+	tests := []struct {
+		hostname string
+		env      string
+		want     string
+	}{
+		{"", "", "https://gitlab.com/api/v4"},
+		{"", "gitlab.example.com", "https://gitlab.example.com/api/v4"},
+		{"git.example.org", "gitlab.example.com", "https://git.example.org/api/v4"},
+	}
+	for _, tt := range tests {
+		t.Setenv("GITLAB_HOST", tt.env)
+		client, err := NewClient("group/project", Options{Hostname: tt.hostname})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if client.http.BaseURL != tt.want || client.http.Header.Get("Private-Token") != "env-token" {
+			t.Errorf("hostname %q, GITLAB_HOST %q: base URL %q, token %q", tt.hostname, tt.env, client.http.BaseURL, client.http.Header.Get("Private-Token"))
+		}
+	}
+
+	t.Setenv("GITLAB_TOKEN", "")
+	if _, err := NewClient("group/project", Options{}); err == nil {
+		t.Error("expected an error without a token")
+	}
+}
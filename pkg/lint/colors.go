@@ -0,0 +1,89 @@
+package lint
+
+import (
+	"fmt"
+
+	"github.com/scttfrdmn/gh-label-sync/pkg/api"
+	"github.com/scttfrdmn/gh-label-sync/pkg/color"
+)
+
+// Rule IDs of the color checks
+const (
+	ContrastRule      = "contrast"
+	NearDuplicateRule = "near-duplicate"
+)
+
+// DefaultMinDistance is the CIEDE2000 difference below which two colors in a
+// group are reported as near-duplicates
+const DefaultMinDistance = 6.0
+
+// CheckContrast reports labels whose text, in the color GitHub picks for
+// them, has less than the given WCAG contrast ratio, suggesting the closest
+// color that has enough. Colors that are not hex, such as ${name} templates,
+// are skipped.
+func CheckContrast(labels []api.Label, minContrast float64) []Violation {
+	var violations []Violation
+	for i, label := range labels {
+		c, err := color.Parse(label.Color)
+		if label.Delete || err != nil {
+			continue
+		}
+
+		ratio := c.TextContrast()
+		if ratio >= minContrast {
+			continue
+		}
+
+		text := "white"
+		if c.TextColor() == color.Black {
+			text = "black"
+		}
+		violations = append(violations, Violation{
+			Rule:       ContrastRule,
+			Severity:   SeverityError,
+			Label:      label.Name,
+			Index:      i,
+			Message:    fmt.Sprintf("%s text on #%s has a contrast of %.2f:1, below %.1f:1", text, c.Hex(), ratio, minContrast),
+			Suggestion: c.Accessible(minContrast).Hex(),
+		})
+	}
+	return violations
+}
+
+// CheckNearDuplicates reports labels whose colors are closer than minDistance
+// (CIEDE2000) to an earlier label in the same group. Labels are grouped by
+// the prefix before their first ":" or "/", and labels without one form a
+// group of their own. Colors that are not hex are skipped.
+func CheckNearDuplicates(labels []api.Label, minDistance float64) []Violation {
+	type entry struct {
+		name  string
+		color color.RGB
+	}
+
+	var violations []Violation
+	groups := make(map[string][]entry)
+	for i, label := range labels {
+		c, err := color.Parse(label.Color)
+		if label.Delete || err != nil {
+			continue
+		}
+
+		group, _ := api.SplitScope(label.Name)
+		for _, other := range groups[group] {
+			d := color.DeltaE(other.color, c)
+			if d >= minDistance {
+				continue
+			}
+			violations = append(violations, Violation{
+				Rule:     NearDuplicateRule,
+				Severity: SeverityWarning,
+				Label:    label.Name,
+				Index:    i,
+				Message:  fmt.Sprintf("color #%s is hard to tell apart from %q (#%s, ΔE %.1f)", c.Hex(), other.name, other.color.Hex(), d),
+			})
+			break
+		}
+		groups[group] = append(groups[group], entry{label.Name, c})
+	}
+	return violations
+}
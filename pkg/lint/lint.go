@@ -0,0 +1,364 @@
+package lint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/scttfrdmn/gh-label-sync/pkg/api"
+	"github.com/scttfrdmn/gh-label-sync/pkg/color"
+	"github.com/scttfrdmn/gh-label-sync/pkg/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPolicyFile is where lint looks for rules when none are given
+const DefaultPolicyFile = ".github/label-policy.yml"
+
+// MaxLabelsRule is the rule ID of the policy's total label limit
+const MaxLabelsRule = "max-labels"
+
+// Severity is how serious a violation is
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Policy is a set of rules label definitions must follow
+type Policy struct {
+	// MaxLabels limits the total number of labels; zero means no limit
+	MaxLabels int `yaml:"max-labels"`
+
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule checks the labels whose names match a glob. Every check that is set
+// applies.
+type Rule struct {
+	// ID names the rule in violations; defaults to rule-N
+	ID string `yaml:"id"`
+
+	// Match is a glob selecting the labels the rule applies to; empty
+	// selects every label
+	Match string `yaml:"match"`
+
+	// Severity defaults to error
+	Severity Severity `yaml:"severity"`
+
+	// Message replaces the violation message
+	Message string `yaml:"message"`
+
+	// Name is a regular expression label names must match
+	Name string `yaml:"name"`
+
+	// Palette lists the allowed colors, as hue families (red, orange,
+	// yellow, green, cyan, blue, purple, pink, gray) or hex colors
+	Palette []string `yaml:"palette"`
+
+	// RequireDescription requires a non-empty description
+	RequireDescription bool `yaml:"require-description"`
+
+	// MaxLabels limits how many labels the rule matches
+	MaxLabels int `yaml:"max-labels"`
+
+	pattern *regexp.Regexp
+}
+
+// Violation is a label that breaks a rule. Index is the label's position in
+// the linted list, or -1 for violations about the list as a whole.
+type Violation struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Label    string   `json:"label,omitempty"`
+	Index    int      `json:"-"`
+	Message  string   `json:"message"`
+
+	// Suggestion is a replacement color, as 6 hex digits, when one would
+	// fix the violation
+	Suggestion string `json:"suggestion,omitempty"`
+
+	// File, Line, and Column locate the label when linting a file
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+}
+
+// LoadPolicy reads and validates a policy file. The error for a missing file
+// wraps fs.ErrNotExist.
+func LoadPolicy(filename string) (*Policy, error) {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, &missingPolicyError{filename: filename}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open policy file: %w", err)
+	}
+	return ParsePolicy(data)
+}
+
+// missingPolicyError reports a policy file that does not exist
+type missingPolicyError struct {
+	filename string
+}
+
+func (e *missingPolicyError) Error() string {
+	return fmt.Sprintf("policy file %s not found (use --policy)", e.filename)
+}
+
+func (e *missingPolicyError) Unwrap() error {
+	return fs.ErrNotExist
+}
+
+// ParsePolicy parses and validates a policy
+func ParsePolicy(data []byte) (*Policy, error) {
+	var policy Policy
+	if err := yaml.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %w", err)
+	}
+
+	for i := range policy.Rules {
+		if err := policy.Rules[i].compile(i); err != nil {
+			return nil, err
+		}
+	}
+
+	return &policy, nil
+}
+
+// compile fills in defaults and checks the rule's patterns and palette
+func (r *Rule) compile(i int) error {
+	if r.ID == "" {
+		r.ID = fmt.Sprintf("rule-%d", i+1)
+	}
+
+	switch r.Severity {
+	case "":
+		r.Severity = SeverityError
+	case SeverityError, SeverityWarning:
+	default:
+		return fmt.Errorf("rule %s: invalid severity %q (use error or warning)", r.ID, r.Severity)
+	}
+
+	if r.Match != "" {
+		if err := api.ValidateGlob(r.Match); err != nil {
+			return fmt.Errorf("rule %s: invalid match pattern %q", r.ID, r.Match)
+		}
+	}
+
+	if r.Name != "" {
+		pattern, err := regexp.Compile(r.Name)
+		if err != nil {
+			return fmt.Errorf("rule %s: invalid name pattern: %w", r.ID, err)
+		}
+		r.pattern = pattern
+	}
+
+	for _, entry := range r.Palette {
+		if slices.Contains(color.Families, strings.ToLower(entry)) {
+			continue
+		}
+		if _, err := color.Parse(entry); err != nil {
+			return fmt.Errorf("rule %s: palette entry %q is not a color family (%s) or hex color", r.ID, entry, strings.Join(color.Families, ", "))
+		}
+	}
+
+	if r.pattern == nil && len(r.Palette) == 0 && !r.RequireDescription && r.MaxLabels == 0 {
+		return fmt.Errorf("rule %s has no checks (set name, palette, require-description, or max-labels)", r.ID)
+	}
+
+	return nil
+}
+
+// Lint checks labels against the policy. Labels marked for deletion are
+// skipped. Violations are ordered by label.
+func (p *Policy) Lint(labels []api.Label) []Violation {
+	var violations []Violation
+
+	total := 0
+	for _, label := range labels {
+		if !label.Delete {
+			total++
+		}
+	}
+	if p.MaxLabels > 0 && total > p.MaxLabels {
+		violations = append(violations, Violation{
+			Rule:     MaxLabelsRule,
+			Severity: SeverityError,
+			Index:    -1,
+			Message:  fmt.Sprintf("%d labels exceed the limit of %d", total, p.MaxLabels),
+		})
+	}
+
+	for _, rule := range p.Rules {
+		violations = append(violations, rule.lint(labels)...)
+	}
+
+	// Report in file order, with violations about the whole list first
+	slices.SortStableFunc(violations, func(a, b Violation) int { return a.Index - b.Index })
+
+	return violations
+}
+
+func (r *Rule) lint(labels []api.Label) []Violation {
+	var violations []Violation
+	report := func(i int, format string, args ...any) {
+		v := Violation{Rule: r.ID, Severity: r.Severity, Index: i, Message: fmt.Sprintf(format, args...)}
+		if i >= 0 {
+			v.Label = labels[i].Name
+		}
+		if r.Message != "" {
+			v.Message = r.Message
+		}
+		violations = append(violations, v)
+	}
+
+	matched := 0
+	for i, label := range labels {
+		if label.Delete || !r.matches(label.Name) {
+			continue
+		}
+		matched++
+
+		// A ${name} template only has a value once it is expanded for a
+		// repository, so it is not checked
+		if r.pattern != nil && !parser.IsTemplate(label.Name) && !r.pattern.MatchString(label.Name) {
+			report(i, "name does not match %q", r.pattern)
+		}
+		if len(r.Palette) > 0 && !parser.IsTemplate(label.Color) && !r.inPalette(label.Color) {
+			report(i, "color #%s is not in the palette (%s)", api.NormalizeColor(label.Color), strings.Join(r.Palette, ", "))
+		}
+		if r.RequireDescription && strings.TrimSpace(label.Description) == "" {
+			report(i, "description is required")
+		}
+	}
+
+	if r.MaxLabels > 0 && matched > r.MaxLabels {
+		report(-1, "%d labels match %q, more than the limit of %d", matched, r.matchPattern(), r.MaxLabels)
+	}
+
+	return violations
+}
+
+// Describe summarizes what the rule requires
+func (r *Rule) Describe() string {
+	if r.Message != "" {
+		return r.Message
+	}
+
+	var checks []string
+	if r.pattern != nil {
+		checks = append(checks, fmt.Sprintf("have names matching %q", r.Name))
+	}
+	if len(r.Palette) > 0 {
+		checks = append(checks, "use colors from "+strings.Join(r.Palette, ", "))
+	}
+	if r.RequireDescription {
+		checks = append(checks, "have descriptions")
+	}
+
+	subject := "Labels"
+	if r.Match != "" {
+		subject = fmt.Sprintf("Labels matching %q", r.Match)
+	}
+
+	var parts []string
+	if len(checks) > 0 {
+		parts = append(parts, subject+" must "+strings.Join(checks, " and "))
+	}
+	if r.MaxLabels > 0 {
+		parts = append(parts, fmt.Sprintf("At most %d labels may match %q", r.MaxLabels, r.matchPattern()))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func (r *Rule) matches(name string) bool {
+	if r.Match == "" {
+		return true
+	}
+	return api.MatchGlob(r.Match, name)
+}
+
+func (r *Rule) matchPattern() string {
+	if r.Match == "" {
+		return "*"
+	}
+	return r.Match
+}
+
+// inPalette reports whether a color is one of the palette's hex colors or
+// belongs to one of its hue families
+func (r *Rule) inPalette(hex string) bool {
+	c, err := color.Parse(hex)
+	if err != nil {
+		return false
+	}
+
+	for _, entry := range r.Palette {
+		if strings.EqualFold(entry, c.Family()) {
+			return true
+		}
+		if p, err := color.Parse(entry); err == nil && p == c {
+			return true
+		}
+	}
+	return false
+}
+
+// Errors returns the number of violations with error severity
+func Errors(violations []Violation) int {
+	n := 0
+	for _, v := range violations {
+		if v.Severity == SeverityError {
+			n++
+		}
+	}
+	return n
+}
+
+// WriteText writes one violation per line, prefixed with its location
+func WriteText(w io.Writer, violations []Violation) error {
+	for _, v := range violations {
+		loc := v.File
+		if v.Line > 0 {
+			loc = fmt.Sprintf("%s:%d:%d", v.File, v.Line, v.Column)
+		}
+		if loc != "" {
+			loc += ": "
+		}
+
+		subject := ""
+		if v.Label != "" {
+			subject = v.Label + ": "
+		}
+
+		suggestion := ""
+		if v.Suggestion != "" {
+			suggestion = fmt.Sprintf(" (try #%s)", v.Suggestion)
+		}
+
+		if _, err := fmt.Fprintf(w, "%s%s: %s%s%s [%s]\n", loc, v.Severity, subject, v.Message, suggestion, v.Rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteJSON writes the violations as a JSON array
+func WriteJSON(w io.Writer, violations []Violation) error {
+	if violations == nil {
+		violations = []Violation{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(violations); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+	return nil
+}
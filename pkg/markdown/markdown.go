@@ -0,0 +1,47 @@
+package markdown
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/scttfrdmn/gh-label-sync/pkg/api"
+)
+
+// Swatch returns an inline image showing the given color, followed by its
+// hex code
+func Swatch(hex string) string {
+	return fmt.Sprintf("![#%s](https://img.shields.io/badge/-%%20-%s?style=flat-square) `%s`", hex, hex, hex)
+}
+
+// Badge returns an inline image of a label as GitHub would show it
+func Badge(name, hex string) string {
+	// shields.io reads - and _ as separators and spaces, so double them
+	text := strings.NewReplacer("-", "--", "_", "__", " ", "_").Replace(name)
+	alt := strings.NewReplacer("[", "\\[", "]", "\\]").Replace(Escape(name))
+	return fmt.Sprintf("![%s](https://img.shields.io/badge/%s-%s)", alt, url.PathEscape(text), hex)
+}
+
+// LabelTable formats labels as a Markdown table, e.g. for a README. Labels
+// marked for deletion are left out.
+func LabelTable(labels []api.Label) string {
+	var sb strings.Builder
+
+	sb.WriteString("| Label | Color | Description |\n")
+	sb.WriteString("|---|---|---|\n")
+
+	for _, l := range labels {
+		if l.Delete {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("| %s | `#%s` | %s |\n", Badge(l.Name, l.Color), l.Color, Escape(l.Description)))
+	}
+
+	return sb.String()
+}
+
+// Escape escapes characters that would break a table cell
+func Escape(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "\n", " ")
+}
@@ -0,0 +1,110 @@
+package migrate
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/scttfrdmn/gh-label-sync/pkg/api"
+	"gopkg.in/yaml.v3"
+)
+
+// RenameRule maps a source label name to a target name. When both sides end
+// in *, the rule maps a prefix: "kind/*" → "type: *" renames "kind/bug" to
+// "type: bug".
+type RenameRule struct {
+	From string `json:"from" yaml:"from"`
+	To   string `json:"to" yaml:"to"`
+}
+
+type ruleFile struct {
+	Renames []RenameRule `yaml:"renames"`
+}
+
+// ParseRule parses a rule written as old=new
+func ParseRule(s string) (RenameRule, error) {
+	from, to, ok := strings.Cut(s, "=")
+	if !ok || from == "" || to == "" {
+		return RenameRule{}, fmt.Errorf("invalid rename rule %q (use old=new)", s)
+	}
+
+	rule := RenameRule{From: from, To: to}
+	return rule, rule.validate()
+}
+
+// LoadRules reads rename rules from a YAML file with a top-level renames list
+func LoadRules(filename string) ([]RenameRule, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open rename rules: %w", err)
+	}
+
+	var rf ruleFile
+	if err := yaml.Unmarshal(data, &rf); err != nil {
+		return nil, fmt.Errorf("failed to parse rename rules: %w", err)
+	}
+
+	for _, rule := range rf.Renames {
+		if err := rule.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return rf.Renames, nil
+}
+
+func (r RenameRule) validate() error {
+	if r.From == "" || r.To == "" {
+		return fmt.Errorf("invalid rename rule %q → %q: both names are required", r.From, r.To)
+	}
+	if strings.HasSuffix(r.From, "*") != strings.HasSuffix(r.To, "*") {
+		return fmt.Errorf("invalid rename rule %q → %q: use * on both sides or neither", r.From, r.To)
+	}
+	return nil
+}
+
+// apply returns the renamed name and whether the rule matched
+func (r RenameRule) apply(name string) (string, bool) {
+	if prefix, ok := strings.CutSuffix(r.From, "*"); ok {
+		if rest, ok := strings.CutPrefix(name, prefix); ok {
+			return strings.TrimSuffix(r.To, "*") + rest, true
+		}
+		return name, false
+	}
+	if name == r.From {
+		return r.To, true
+	}
+	return name, false
+}
+
+// Rename applies the first matching rule to each label and returns the
+// renamed labels with a map from each new name to its source name. It is an
+// error for two labels to end up with the same name.
+func Rename(labels []api.Label, rules []RenameRule) ([]api.Label, map[string]string, error) {
+	renamed := make([]api.Label, 0, len(labels))
+	sources := make(map[string]string, len(labels))
+
+	// Label names are case-insensitive, so collisions are too
+	seen := make(map[string]string, len(labels))
+
+	for _, label := range labels {
+		source := label.Name
+		for _, rule := range rules {
+			if name, ok := rule.apply(label.Name); ok {
+				label.Name = name
+				break
+			}
+		}
+
+		key := strings.ToLower(label.Name)
+		if other, ok := seen[key]; ok {
+			return nil, nil, fmt.Errorf("labels %q and %q both map to %q", other, source, label.Name)
+		}
+
+		seen[key] = source
+		sources[label.Name] = source
+		renamed = append(renamed, label)
+	}
+
+	return renamed, sources, nil
+}
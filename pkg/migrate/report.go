@@ -0,0 +1,92 @@
+package migrate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Actions recorded for each label in a migration report
+const (
+	ActionCreated   = "created"
+	ActionUpdated   = "updated"
+	ActionUnchanged = "unchanged"
+	ActionSkipped   = "skipped"
+	ActionFailed    = "failed"
+	ActionPlanned   = "planned"
+)
+
+// Entry records what happened to one source label
+type Entry struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Action string `json:"action"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Report summarizes a label migration between two repositories
+type Report struct {
+	Source  string  `json:"source"`
+	Target  string  `json:"target"`
+	Entries []Entry `json:"entries"`
+}
+
+// Add records an entry
+func (r *Report) Add(source, target, action, detail string) {
+	r.Entries = append(r.Entries, Entry{Source: source, Target: target, Action: action, Detail: detail})
+}
+
+// Counts returns the number of entries for each action
+func (r *Report) Counts() map[string]int {
+	counts := make(map[string]int)
+	for _, e := range r.Entries {
+		counts[e.Action]++
+	}
+	return counts
+}
+
+// WriteJSON writes the report as JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(r); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// WriteMarkdown writes the report as a Markdown document
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	var sb strings.Builder
+
+	sb.WriteString("# Label migration report\n\n")
+	sb.WriteString(fmt.Sprintf("- **Source:** `%s`\n", r.Source))
+	sb.WriteString(fmt.Sprintf("- **Target:** `%s`\n", r.Target))
+
+	counts := r.Counts()
+	for _, action := range []string{ActionCreated, ActionUpdated, ActionUnchanged, ActionSkipped, ActionFailed, ActionPlanned} {
+		if counts[action] > 0 {
+			sb.WriteString(fmt.Sprintf("- **%s:** %d\n", strings.ToUpper(action[:1])+action[1:], counts[action]))
+		}
+	}
+
+	sb.WriteString("\n| Source label | Target label | Action | Detail |\n")
+	sb.WriteString("|---|---|---|---|\n")
+	for _, e := range r.Entries {
+		target := e.Target
+		if target == e.Source {
+			target = "(same)"
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", cell(e.Source), cell(target), e.Action, cell(e.Detail)))
+	}
+
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+func cell(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
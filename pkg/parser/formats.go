@@ -0,0 +1,358 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/scttfrdmn/gh-label-sync/pkg/api"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is a label file format
+type Format string
+
+const (
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+	FormatCSV  Format = "csv"
+	FormatTOML Format = "toml"
+
+	// FormatMarkdown is a table of labels for documentation; it can be
+	// written but not read
+	FormatMarkdown Format = "markdown"
+
+	// FormatLabelSync is the array of labels read by github-label-sync (npm),
+	// with aliases and delete
+	FormatLabelSync Format = "github-label-sync"
+
+	// FormatLabeler is the array of labels read by
+	// crazy-max/ghaction-github-labeler, with from_name
+	FormatLabeler Format = "labeler"
+
+	// FormatProbot is the labels section of a Probot settings file
+	// (.github/settings.yml), with new_name and oldname
+	FormatProbot Format = "probot"
+)
+
+// Formats lists the supported formats
+var Formats = []Format{FormatYAML, FormatJSON, FormatCSV, FormatTOML, FormatMarkdown, FormatLabelSync, FormatLabeler, FormatProbot}
+
+// probotSections are top-level keys of a Probot settings file besides labels
+var probotSections = []string{"repository", "branches", "collaborators", "teams", "milestones", "environments"}
+
+// ParseFormat parses a format name, accepting yml and md as aliases
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "yml":
+		return FormatYAML, nil
+	case "md":
+		return FormatMarkdown, nil
+	}
+	for _, f := range Formats {
+		if Format(strings.ToLower(s)) == f {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported format: %s (use %s)", s, formatList())
+}
+
+func formatList() string {
+	names := make([]string, len(Formats))
+	for i, f := range Formats {
+		names[i] = string(f)
+	}
+	return strings.Join(names, ", ")
+}
+
+// detectStructure refines a YAML or JSON format by looking at the document:
+// a top-level array is github-label-sync or labeler, and a labels section
+// with renames or other settings is Probot
+func detectStructure(data []byte, base Format) Format {
+	var doc yaml.Node
+	if yaml.Unmarshal(data, &doc) != nil || len(doc.Content) == 0 {
+		return base
+	}
+
+	root := doc.Content[0]
+	switch root.Kind {
+	case yaml.SequenceNode:
+		for _, item := range root.Content {
+			if hasKey(item, "from_name") {
+				return FormatLabeler
+			}
+		}
+		return FormatLabelSync
+	case yaml.MappingNode:
+		for _, key := range probotSections {
+			if hasKey(root, key) {
+				return FormatProbot
+			}
+		}
+		if labels := mappingValue(root, "labels"); labels != nil {
+			for _, item := range labels.Content {
+				if hasKey(item, "new_name") || hasKey(item, "oldname") {
+					return FormatProbot
+				}
+			}
+		}
+	}
+
+	return base
+}
+
+// mappingValue returns the value for key in a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func hasKey(node *yaml.Node, key string) bool {
+	return mappingValue(node, key) != nil
+}
+
+// labelSyncLabel is a label as github-label-sync defines it
+type labelSyncLabel struct {
+	Name        string   `json:"name" yaml:"name"`
+	Color       string   `json:"color" yaml:"color"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Aliases     []string `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+	Delete      bool     `json:"delete,omitempty" yaml:"delete,omitempty"`
+}
+
+// labelerLabel is a label as ghaction-github-labeler defines it
+type labelerLabel struct {
+	Name        string `yaml:"name"`
+	Color       string `yaml:"color"`
+	Description string `yaml:"description,omitempty"`
+	FromName    string `yaml:"from_name,omitempty"`
+}
+
+// probotLabel is a label in a Probot settings file. A label with new_name
+// renames name to new_name; a label with oldname renames oldname to name.
+type probotLabel struct {
+	Name        string `yaml:"name"`
+	NewName     string `yaml:"new_name,omitempty"`
+	OldName     string `yaml:"oldname,omitempty"`
+	Color       string `yaml:"color"`
+	Description string `yaml:"description,omitempty"`
+}
+
+type probotFile struct {
+	Labels []probotLabel `yaml:"labels"`
+}
+
+// parseLabelSync parses a github-label-sync file, which may be JSON or YAML
+func parseLabelSync(r io.Reader) ([]api.Label, error) {
+	var items []labelSyncLabel
+	if err := yaml.NewDecoder(r).Decode(&items); err != nil {
+		return nil, fmt.Errorf("failed to parse github-label-sync file: %w", err)
+	}
+
+	labels := make([]api.Label, len(items))
+	for i, item := range items {
+		labels[i] = api.Label{
+			Name:        item.Name,
+			Color:       item.Color,
+			Description: item.Description,
+			Aliases:     item.Aliases,
+			Delete:      item.Delete,
+		}
+	}
+	return labels, nil
+}
+
+// parseLabeler parses a ghaction-github-labeler file
+func parseLabeler(r io.Reader) ([]api.Label, error) {
+	var items []labelerLabel
+	if err := yaml.NewDecoder(r).Decode(&items); err != nil {
+		return nil, fmt.Errorf("failed to parse labeler file: %w", err)
+	}
+
+	labels := make([]api.Label, len(items))
+	for i, item := range items {
+		labels[i] = api.Label{
+			Name:        item.Name,
+			Color:       item.Color,
+			Description: item.Description,
+		}
+		if item.FromName != "" {
+			labels[i].Aliases = []string{item.FromName}
+		}
+	}
+	return labels, nil
+}
+
+// parseProbot parses the labels section of a Probot settings file
+func parseProbot(r io.Reader) ([]api.Label, error) {
+	var file probotFile
+	if err := yaml.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to parse Probot settings: %w", err)
+	}
+
+	labels := make([]api.Label, len(file.Labels))
+	for i, item := range file.Labels {
+		labels[i] = api.Label{
+			Name:        item.Name,
+			Color:       item.Color,
+			Description: item.Description,
+		}
+		if item.NewName != "" {
+			labels[i].Name = item.NewName
+			labels[i].Aliases = []string{item.Name}
+		}
+		if item.OldName != "" {
+			labels[i].Aliases = append(labels[i].Aliases, item.OldName)
+		}
+	}
+	return labels, nil
+}
+
+// WriteFormat writes labels in the given format. Fields the format cannot
+// represent are dropped; see ConversionWarnings.
+func WriteFormat(w io.Writer, labels []api.Label, format Format) error {
+	switch format {
+	case FormatYAML:
+		return WriteYAML(w, labels)
+	case FormatJSON:
+		return WriteJSON(w, labels)
+	case FormatCSV:
+		return WriteCSV(w, labels)
+	case FormatTOML:
+		return WriteTOML(w, labels)
+	case FormatMarkdown:
+		return WriteMarkdown(w, labels)
+	case FormatLabelSync:
+		items := make([]labelSyncLabel, len(labels))
+		for i, l := range labels {
+			items[i] = labelSyncLabel{
+				Name:        l.Name,
+				Color:       l.Color,
+				Description: l.Description,
+				Aliases:     l.Aliases,
+				Delete:      l.Delete,
+			}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(items); err != nil {
+			return fmt.Errorf("failed to write github-label-sync file: %w", err)
+		}
+		return nil
+	case FormatLabeler:
+		items := make([]labelerLabel, 0, len(labels))
+		for _, l := range labels {
+			if l.Delete {
+				continue
+			}
+			item := labelerLabel{Name: l.Name, Color: l.Color, Description: l.Description}
+			if len(l.Aliases) > 0 {
+				item.FromName = l.Aliases[0]
+			}
+			items = append(items, item)
+		}
+		return writeYAMLValue(w, items)
+	case FormatProbot:
+		file := probotFile{Labels: make([]probotLabel, 0, len(labels))}
+		for _, l := range labels {
+			if l.Delete {
+				continue
+			}
+			item := probotLabel{Name: l.Name, Color: l.Color, Description: l.Description}
+			if len(l.Aliases) > 0 {
+				item.Name = l.Aliases[0]
+				item.NewName = l.Name
+			}
+			file.Labels = append(file.Labels, item)
+		}
+		return writeYAMLValue(w, file)
+	default:
+		return fmt.Errorf("cannot write %s files", format)
+	}
+}
+
+func writeYAMLValue(w io.Writer, v any) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("failed to write YAML: %w", err)
+	}
+	return nil
+}
+
+// Convert writes the contents of a label file in another format, and returns
+// warnings for anything the format cannot represent. Converting to YAML, JSON,
+// or TOML keeps the file's policies, vars, and groups as written; other
+// formats get the labels, with groups flattened and policy rules applied.
+func Convert(w io.Writer, filename string, data []byte, from, to Format) ([]string, error) {
+	labelFile, err := ParseLabelData(filename, data, from, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var warnings []string
+	if to == FormatYAML || to == FormatJSON || to == FormatTOML {
+		raw, err := decodeLabelData(filename, data, from)
+		if err != nil {
+			return nil, err
+		}
+		if len(raw.Policies) > 0 || len(raw.Vars) > 0 || len(raw.Groups) > 0 {
+			return nil, writeLabelFile(w, raw, to)
+		}
+	} else {
+		if len(labelFile.Vars) > 0 {
+			warnings = append(warnings, fmt.Sprintf("vars: %s has no template variables; dropped, so ${name} references are left unexpanded", to))
+		}
+		if len(labelFile.Policies) > 0 {
+			warnings = append(warnings, fmt.Sprintf("policies: %s has no policy rules; dropped", to))
+		}
+	}
+	warnings = append(warnings, ConversionWarnings(labelFile.Labels, to)...)
+
+	return warnings, WriteFormat(w, labelFile.Labels, to)
+}
+
+// ConversionWarnings describes what is lost when labels are written in the
+// given format
+func ConversionWarnings(labels []api.Label, format Format) []string {
+	if format == FormatYAML || format == FormatJSON || format == FormatTOML {
+		return nil
+	}
+
+	// CSV and Markdown have no way to record renames
+	noAliases := format == FormatCSV || format == FormatMarkdown
+
+	var warnings []string
+	for _, l := range labels {
+		if l.Delete && format != FormatLabelSync {
+			warnings = append(warnings, fmt.Sprintf("%s: %s cannot mark labels for deletion; label dropped", l.Name, format))
+			continue
+		}
+		if len(l.Aliases) > 0 && noAliases {
+			warnings = append(warnings, fmt.Sprintf("%s: %s has no aliases; dropped", l.Name, format))
+		} else if len(l.Aliases) > 1 && format != FormatLabelSync {
+			warnings = append(warnings, fmt.Sprintf("%s: %s supports one previous name; kept %q", l.Name, format, l.Aliases[0]))
+		}
+		if l.Priority != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: %s has no priority; dropped", l.Name, format))
+		}
+		if l.Exclusive != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: %s has no exclusive flag; dropped", l.Name, format))
+		}
+		if l.Policy != api.PolicyDefault || l.Protected {
+			warnings = append(warnings, fmt.Sprintf("%s: %s has no sync policy; dropped", l.Name, format))
+		}
+		if l.When != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: %s has no conditions; label kept for every repository", l.Name, format))
+		}
+	}
+	return warnings
+}
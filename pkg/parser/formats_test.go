@@ -0,0 +1,44 @@
+package parser
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestConvertKeepsFileBlocks(t *testing.T) {
+	data := []byte(`vars:
+  team: web
+policies:
+  - match: "wont*"
+    policy: create-only
+labels:
+  - name: "team: ${team}"
+    color: d73a4a
+`)
+
+	var buf bytes.Buffer
+	warnings, err := Convert(&buf, "labels.yml", data, "", FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("warnings = %v, want none", warnings)
+	}
+	labelFile, err := parseLabelFile(buf.Bytes(), FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if labelFile.Vars["team"] != "web" || len(labelFile.Policies) != 1 || labelFile.Labels[0].Policy != "" {
+		t.Errorf("vars or policies not kept as written:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	warnings, err = Convert(&buf, "labels.yml", data, "", FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) < 2 || !strings.HasPrefix(warnings[0], "vars:") || !strings.HasPrefix(warnings[1], "policies:") {
+		t.Errorf("warnings = %v, want vars and policies reported as dropped", warnings)
+	}
+}
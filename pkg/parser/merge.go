@@ -0,0 +1,539 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/scttfrdmn/gh-label-sync/pkg/api"
+	"gopkg.in/yaml.v3"
+)
+
+// MergeResult counts the labels MergeYAML changed
+type MergeResult struct {
+	Updated int
+	Added   int
+}
+
+// Merge merges labels into a label file in the given format. Files written
+// as YAML, including other tools' formats and Probot settings, are edited in
+// place with MergeYAML's rules, so comments and unrelated settings survive.
+// JSON, TOML, CSV, and github-label-sync JSON files are rewritten. Labeler and
+// Probot files written as JSON are refused, since only their labels could be
+// written back.
+func Merge(data []byte, format Format, labels []api.Label) ([]byte, MergeResult, error) {
+	switch format {
+	case FormatYAML:
+		return MergeYAML(data, labels)
+	case FormatLabelSync, FormatLabeler, FormatProbot:
+		if !isJSON(data) {
+			return mergeYAML(data, labels, format)
+		}
+		if format != FormatLabelSync {
+			return nil, MergeResult{}, fmt.Errorf("cannot update %s files written as JSON", format)
+		}
+	case FormatMarkdown:
+		return nil, MergeResult{}, fmt.Errorf("cannot update %s files", format)
+	}
+	return rewrite(data, format, labels)
+}
+
+// MergeYAML merges labels into an existing YAML label file, editing the
+// document in place so comments, key order, and grouping survive. Only values
+// that differ are rewritten, including for labels in groups; labels missing
+// from the file are appended, and labels only in the file are left alone.
+// Values written as ${name} templates are never replaced, and labels whose
+// name a templated name could expand to are not appended.
+func MergeYAML(data []byte, labels []api.Label) ([]byte, MergeResult, error) {
+	return mergeYAML(data, labels, FormatYAML)
+}
+
+// mergeYAML merges labels into a YAML document in the given format. Fields
+// the format does not have, such as priority, are never added.
+func mergeYAML(data []byte, labels []api.Label, format Format) ([]byte, MergeResult, error) {
+	var result MergeResult
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, result, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	items, err := labelSequence(&doc)
+	if err != nil {
+		return nil, result, err
+	}
+
+	groups, err := groupSequences(&doc)
+	if err != nil {
+		return nil, result, err
+	}
+
+	// Index existing definitions by name and condition. A Probot label with
+	// new_name is the label of that name.
+	var defs []definition[*yaml.Node]
+	var templates []string
+	for _, seq := range append([]groupSequence{{labels: items}}, groups...) {
+		for _, item := range seq.labels.Content {
+			name := mappingValue(item, "name")
+			if format == FormatProbot && hasKey(item, "new_name") {
+				name = mappingValue(item, "new_name")
+			}
+			if name == nil {
+				continue
+			}
+			var when *api.Condition
+			if node := mappingValue(item, "when"); node != nil {
+				if err := node.Decode(&when); err != nil {
+					return nil, result, fmt.Errorf("label %q: when: %w", name.Value, err)
+				}
+			}
+			defs = append(defs, definition[*yaml.Node]{name: name.Value, when: when.And(seq.when), item: item})
+			if IsTemplate(name.Value) {
+				templates = append(templates, name.Value)
+			}
+		}
+	}
+
+	// New entries copy the style of existing ones
+	style := entryStyleOf(items, format)
+
+	// yaml.v3 drops blank lines, so note which entries start a group
+	lines := strings.Split(string(data), "\n")
+	separated := make([]bool, len(items.Content))
+	for i, item := range items.Content {
+		separated[i] = blankBefore(lines, item.Line)
+	}
+
+	for _, label := range labels {
+		item, ok, err := findDefinition(defs, label)
+		if err != nil {
+			return nil, result, err
+		}
+		if !ok && templateCovers(templates, label.Name) {
+			continue
+		}
+		if !ok {
+			items.Content = append(items.Content, labelNode(label, style))
+			separated = append(separated, len(separated) > 0 && separated[len(separated)-1])
+			result.Added++
+			continue
+		}
+		if mergeLabel(item, label, style) {
+			result.Updated++
+		}
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, result, fmt.Errorf("failed to write YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, result, fmt.Errorf("failed to write YAML: %w", err)
+	}
+
+	merged, err := restoreBlankLines(buf.Bytes(), separated)
+	if err != nil {
+		return nil, result, err
+	}
+
+	return merged, result, nil
+}
+
+// blankBefore reports whether the entry starting on the 1-based line, or the
+// comments directly above it, follows a blank line
+func blankBefore(lines []string, line int) bool {
+	i := line - 2
+	for i >= 0 && strings.HasPrefix(strings.TrimSpace(lines[i]), "#") {
+		i--
+	}
+	return i >= 0 && strings.TrimSpace(lines[i]) == ""
+}
+
+// restoreBlankLines inserts a blank line above each label entry marked as
+// separated, and above its comments
+func restoreBlankLines(data []byte, separated []bool) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	items, err := labelSequence(&doc)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(string(data), "\n")
+	insert := make(map[int]bool)
+	for i, item := range items.Content {
+		if i >= len(separated) || !separated[i] {
+			continue
+		}
+		at := item.Line - 1
+		for at > 0 && strings.HasPrefix(strings.TrimSpace(lines[at-1]), "#") {
+			at--
+		}
+		if at > 0 && strings.TrimSpace(lines[at-1]) != "" {
+			insert[at] = true
+		}
+	}
+
+	var out []string
+	for i, line := range lines {
+		if insert[i] {
+			out = append(out, "")
+		}
+		out = append(out, line)
+	}
+	return []byte(strings.Join(out, "\n")), nil
+}
+
+// labelSequence returns the sequence of label definitions, creating a
+// labels key in an empty document
+func labelSequence(doc *yaml.Node) (*yaml.Node, error) {
+	if len(doc.Content) == 0 {
+		doc.Kind = yaml.DocumentNode
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+
+	root := doc.Content[0]
+	switch root.Kind {
+	case yaml.SequenceNode:
+		return root, nil
+	case yaml.MappingNode:
+		if items := mappingValue(root, "labels"); items != nil {
+			if items.Kind != yaml.SequenceNode {
+				return nil, fmt.Errorf("labels must be a list")
+			}
+			return items, nil
+		}
+		items := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		root.Content = append(root.Content, scalarNode("labels", 0), items)
+		return items, nil
+	default:
+		return nil, fmt.Errorf("label file must contain a labels list")
+	}
+}
+
+// groupSequence is the label sequence of a group, with the group's condition
+type groupSequence struct {
+	labels *yaml.Node
+	when   *api.Condition
+}
+
+// groupSequences returns the label sequences of the document's groups
+func groupSequences(doc *yaml.Node) ([]groupSequence, error) {
+	groups := mappingValue(doc.Content[0], "groups")
+	if groups == nil || groups.Kind != yaml.SequenceNode {
+		return nil, nil
+	}
+
+	var seqs []groupSequence
+	for _, group := range groups.Content {
+		labels := mappingValue(group, "labels")
+		if labels == nil || labels.Kind != yaml.SequenceNode {
+			continue
+		}
+		seq := groupSequence{labels: labels}
+		if when := mappingValue(group, "when"); when != nil {
+			if err := when.Decode(&seq.when); err != nil {
+				return nil, fmt.Errorf("groups: when: %w", err)
+			}
+		}
+		seqs = append(seqs, seq)
+	}
+	return seqs, nil
+}
+
+// definition is a label defined in a file, with its condition combined with
+// that of its group
+type definition[T any] struct {
+	name string
+	when *api.Condition
+	item T
+}
+
+// findDefinition returns the definition of a label: the one with its name
+// and condition, or else the only one with its name. A name defined under
+// several other conditions is an error, since any choice could update the
+// definition for the wrong repositories.
+func findDefinition[T any](defs []definition[T], label api.Label) (T, bool, error) {
+	var named []definition[T]
+	for _, d := range defs {
+		if d.name != label.Name {
+			continue
+		}
+		if reflect.DeepEqual(d.when, label.When) {
+			return d.item, true, nil
+		}
+		named = append(named, d)
+	}
+
+	var zero T
+	switch len(named) {
+	case 0:
+		return zero, false, nil
+	case 1:
+		return named[0].item, true, nil
+	}
+	return zero, false, fmt.Errorf("label %q is defined %d times under different conditions; cannot tell which to update", label.Name, len(named))
+}
+
+// entryStyle is how a file writes its label definitions
+type entryStyle struct {
+	// quote is the style of label names and other strings
+	quote yaml.Style
+
+	// hash is set when colors have a leading #
+	hash bool
+
+	// native is set for the tool's own format, which records priority and
+	// exclusive
+	native bool
+}
+
+// entryStyleOf returns the style of the first label in the sequence
+func entryStyleOf(items *yaml.Node, format Format) entryStyle {
+	style := entryStyle{quote: yaml.DoubleQuotedStyle, native: format == FormatYAML}
+	for _, item := range items.Content {
+		if name := mappingValue(item, "name"); name != nil {
+			style.quote = name.Style
+			if color := mappingValue(item, "color"); color != nil {
+				style.hash = strings.HasPrefix(color.Value, "#")
+			}
+			break
+		}
+	}
+	return style
+}
+
+// color returns a color as the file writes it
+func (s entryStyle) color(hex string) string {
+	if s.hash {
+		return "#" + hex
+	}
+	return hex
+}
+
+// mergeLabel updates the fields of a label definition that differ, and
+// reports whether anything changed. Templates are kept, since they stand for
+// a value per repository.
+func mergeLabel(item *yaml.Node, label api.Label, style entryStyle) bool {
+	changed := false
+
+	if color := mappingValue(item, "color"); color == nil {
+		setMappingValue(item, "color", style.color(label.Color), style.quote)
+		changed = true
+	} else if !IsTemplate(color.Value) && api.NormalizeColor(color.Value) != api.NormalizeColor(label.Color) {
+		// Keep a leading # if the file uses one
+		value := label.Color
+		if strings.HasPrefix(color.Value, "#") {
+			value = "#" + value
+		}
+		color.Value = value
+		changed = true
+	}
+
+	if desc := mappingValue(item, "description"); desc == nil {
+		if label.Description != "" {
+			setMappingValue(item, "description", label.Description, style.quote)
+			changed = true
+		}
+	} else if !IsTemplate(desc.Value) && desc.Value != label.Description {
+		desc.Value = label.Description
+		changed = true
+	}
+
+	if !style.native {
+		return changed
+	}
+
+	if label.Priority != nil {
+		value := strconv.Itoa(*label.Priority)
+		if p := mappingValue(item, "priority"); p == nil || p.Value != value {
+			setMappingValue(item, "priority", value, 0)
+			changed = true
+		}
+	}
+
+	// Only record exclusive when it is set, or the file already tracks it
+	if label.Exclusive != nil {
+		value := strconv.FormatBool(*label.Exclusive)
+		e := mappingValue(item, "exclusive")
+		if (e == nil && *label.Exclusive) || (e != nil && e.Value != value) {
+			setMappingValue(item, "exclusive", value, 0)
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+// labelNode builds a new label definition
+func labelNode(label api.Label, style entryStyle) *yaml.Node {
+	item := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	setMappingValue(item, "name", label.Name, style.quote)
+	setMappingValue(item, "color", style.color(label.Color), style.quote)
+	setMappingValue(item, "description", label.Description, style.quote)
+	if !style.native {
+		return item
+	}
+	if label.Priority != nil {
+		setMappingValue(item, "priority", strconv.Itoa(*label.Priority), 0)
+	}
+	if label.Exclusive != nil && *label.Exclusive {
+		setMappingValue(item, "exclusive", "true", 0)
+	}
+	return item
+}
+
+// setMappingValue sets key to a scalar value, adding the key if needed
+func setMappingValue(node *yaml.Node, key, value string, style yaml.Style) {
+	if existing := mappingValue(node, key); existing != nil {
+		existing.Value = value
+		existing.Tag = ""
+		if value == "" {
+			existing.Style = yaml.DoubleQuotedStyle
+		}
+		return
+	}
+	node.Content = append(node.Content, scalarNode(key, 0), scalarNode(value, style))
+}
+
+func scalarNode(value string, style yaml.Style) *yaml.Node {
+	// A plain empty value would read back as null
+	if value == "" {
+		style = yaml.DoubleQuotedStyle
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Value: value, Style: style}
+}
+
+// rewrite merges labels into a file that cannot be edited in place, writing
+// it out again. JSON and TOML label files are decoded as written, so their
+// policies, vars, and groups are written back unchanged and policy rules are
+// not copied into the labels.
+func rewrite(data []byte, format Format, labels []api.Label) ([]byte, MergeResult, error) {
+	var result MergeResult
+
+	native := format == FormatJSON || format == FormatTOML
+	var labelFile *LabelFile
+	var err error
+	if native {
+		labelFile, err = parseLabelFile(data, format)
+	} else {
+		labelFile = &LabelFile{}
+		labelFile.Labels, err = Parse(data, format)
+	}
+	if err != nil {
+		return nil, result, err
+	}
+
+	// Index existing definitions by name and condition
+	var defs []definition[*api.Label]
+	var templates []string
+	index := func(fileLabels []api.Label, groupWhen *api.Condition) {
+		for i := range fileLabels {
+			l := &fileLabels[i]
+			defs = append(defs, definition[*api.Label]{name: l.Name, when: l.When.And(groupWhen), item: l})
+			if IsTemplate(l.Name) {
+				templates = append(templates, l.Name)
+			}
+		}
+	}
+	index(labelFile.Labels, nil)
+	for _, group := range labelFile.Groups {
+		index(group.Labels, group.When)
+	}
+
+	// Labels not in the file yet are appended once the definitions, which
+	// point into the label list, are no longer needed
+	var added []api.Label
+	for _, l := range labels {
+		fileLabel, ok, err := findDefinition(defs, l)
+		if err != nil {
+			return nil, result, err
+		}
+		switch {
+		case ok:
+			if mergeFields(fileLabel, l, native) {
+				result.Updated++
+			}
+		case !templateCovers(templates, l.Name):
+			added = append(added, l)
+		}
+	}
+	labelFile.Labels = append(labelFile.Labels, added...)
+	result.Added = len(added)
+
+	var buf bytes.Buffer
+	if native {
+		err = writeLabelFile(&buf, labelFile, format)
+	} else {
+		err = WriteFormat(&buf, labelFile.Labels, format)
+	}
+	if err != nil {
+		return nil, result, err
+	}
+	return buf.Bytes(), result, nil
+}
+
+// mergeFields copies the values of label that differ into a label from a
+// file, and reports whether anything changed. A leading # on the color and
+// ${name} templates are kept. As in mergeLabel, priority and exclusive are
+// only written for the tool's own formats, and only when label sets them.
+func mergeFields(fileLabel *api.Label, label api.Label, native bool) bool {
+	changed := false
+
+	if !IsTemplate(fileLabel.Color) && api.NormalizeColor(fileLabel.Color) != api.NormalizeColor(label.Color) {
+		hash := strings.HasPrefix(fileLabel.Color, "#")
+		fileLabel.Color = label.Color
+		if hash {
+			fileLabel.Color = "#" + label.Color
+		}
+		changed = true
+	}
+	if !IsTemplate(fileLabel.Description) && fileLabel.Description != label.Description {
+		fileLabel.Description = label.Description
+		changed = true
+	}
+
+	if !native {
+		return changed
+	}
+
+	if label.Priority != nil && (fileLabel.Priority == nil || *fileLabel.Priority != *label.Priority) {
+		priority := *label.Priority
+		fileLabel.Priority = &priority
+		changed = true
+	}
+
+	// Only record exclusive when it is set, or the file already tracks it
+	if label.Exclusive != nil {
+		if (fileLabel.Exclusive == nil && *label.Exclusive) || (fileLabel.Exclusive != nil && *fileLabel.Exclusive != *label.Exclusive) {
+			exclusive := *label.Exclusive
+			fileLabel.Exclusive = &exclusive
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+// templateCovers reports whether name is an expansion of one of the
+// templated names, which already manage the label
+func templateCovers(templates []string, name string) bool {
+	for _, t := range templates {
+		if MatchTemplate(t, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// isJSON reports whether a document is written as JSON rather than YAML
+func isJSON(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	return len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{')
+}
@@ -0,0 +1,291 @@
+package parser
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/scttfrdmn/gh-label-sync/pkg/api"
+)
+
+func TestMergeKeepsProbotSettings(t *testing.T) {
+	data := `repository:
+  name: demo
+  has_wiki: false
+
+# Labels managed by Probot
+labels:
+  - name: bug
+    color: d73a4a
+  - name: enhancement
+    new_name: "type: feature"
+    color: a2eeef
+
+branches:
+  - name: main
+    protection:
+      required_pull_request_reviews:
+        required_approving_review_count: 1
+collaborators:
+  - username: octocat
+    permission: push
+`
+	format := DetectFormat(".github/settings.yml", []byte(data))
+	if format != FormatProbot {
+		t.Fatalf("format = %s, want probot", format)
+	}
+
+	merged, result, err := Merge([]byte(data), format, []api.Label{
+		{Name: "bug", Color: "ff0000"},
+		{Name: "type: feature", Color: "00ff00", Description: "New feature"},
+		{Name: "docs", Color: "0075ca"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Updated != 2 || result.Added != 1 {
+		t.Errorf("result = %+v, want 2 updated and 1 added", result)
+	}
+
+	out := string(merged)
+	for _, want := range []string{
+		"repository:\n  name: demo\n  has_wiki: false\n",
+		"# Labels managed by Probot",
+		"  - name: bug\n    color: ff0000\n",
+		"new_name: \"type: feature\"\n    color: 00ff00\n    description: New feature\n",
+		"  - name: docs\n",
+		"required_approving_review_count: 1",
+		"username: octocat",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("merged file is missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "priority") || strings.Contains(out, "exclusive") {
+		t.Errorf("merged Probot file has fields Probot does not read:\n%s", out)
+	}
+}
+
+func TestMergeKeepsLabelerComments(t *testing.T) {
+	data := `# Managed by ghaction-github-labeler
+- name: "bug"
+  color: "#d73a4a"
+  # Renamed in 2024
+  from_name: "defect"
+`
+	merged, _, err := Merge([]byte(data), FormatLabeler, []api.Label{
+		{Name: "bug", Color: "ff0000"},
+		{Name: "docs", Color: "0075ca"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := string(merged)
+	for _, want := range []string{"# Managed by ghaction-github-labeler", "# Renamed in 2024", `color: "#ff0000"`, `color: "#0075ca"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("merged file is missing %q:\n%s", want, out)
+		}
+	}
+	if _, err := Parse(merged, FormatLabeler); err != nil {
+		t.Errorf("merged file does not parse: %v", err)
+	}
+}
+
+func TestMergeJSON(t *testing.T) {
+	labelSync := `[{"name": "bug", "color": "d73a4a", "aliases": ["defect"]}]`
+	merged, _, err := Merge([]byte(labelSync), FormatLabelSync, []api.Label{{Name: "bug", Color: "ff0000"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isJSON(merged) || !strings.Contains(string(merged), `"ff0000"`) || !strings.Contains(string(merged), `"defect"`) {
+		t.Errorf("github-label-sync JSON not rewritten as JSON with aliases kept:\n%s", merged)
+	}
+
+	labeler := `[{"name": "bug", "color": "d73a4a", "from_name": "defect"}]`
+	if _, _, err := Merge([]byte(labeler), FormatLabeler, nil); err == nil {
+		t.Error("expected labeler JSON to be refused")
+	}
+}
+
+func TestMergeKeepsUnsetFields(t *testing.T) {
+	data := `{"labels": [
+  {"name": "bug", "color": "d73a4a", "priority": 1, "exclusive": true},
+  {"name": "docs", "color": "0075ca", "description": "Documentation"}
+]}`
+	// GitHub labels have no priority or exclusive
+	merged, result, err := Merge([]byte(data), FormatJSON, []api.Label{
+		{Name: "bug", Color: "D73A4A"},
+		{Name: "docs", Color: "0075ca", Description: "Docs"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Updated != 1 {
+		t.Errorf("result = %+v, want only docs updated", result)
+	}
+
+	var file LabelFile
+	if err := json.Unmarshal(merged, &file); err != nil {
+		t.Fatal(err)
+	}
+	if bug := file.Labels[0]; bug.Priority == nil || *bug.Priority != 1 || bug.Exclusive == nil || !*bug.Exclusive {
+		t.Errorf("priority or exclusive of bug not kept:\n%s", merged)
+	}
+}
+
+func TestMergeKeepsFileBlocks(t *testing.T) {
+	data := `{
+  "labels": [
+    {"name": "bug", "color": "#d73a4a"},
+    {"name": "wontfix", "color": "ffffff"}
+  ],
+  "policies": [{"match": "wont*", "policy": "create-only"}],
+  "vars": {"team": "web"},
+  "groups": [
+    {"name": "go", "when": {"language": ["Go"]}, "labels": [{"name": "lang: go", "color": "00add8"}]}
+  ]
+}`
+	merged, result, err := Merge([]byte(data), FormatJSON, []api.Label{
+		{Name: "bug", Color: "ff0000"},
+		{Name: "lang: go", Color: "00aadd", Description: "Go code"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Updated != 2 || result.Added != 0 {
+		t.Errorf("result = %+v, want 2 updated", result)
+	}
+
+	var file LabelFile
+	if err := json.Unmarshal(merged, &file); err != nil {
+		t.Fatal(err)
+	}
+	if file.Labels[0].Color != "#ff0000" {
+		t.Errorf("bug color = %q, want #ff0000", file.Labels[0].Color)
+	}
+	if file.Labels[1].Policy != "" {
+		t.Errorf("wontfix policy = %q, want the rule left in policies", file.Labels[1].Policy)
+	}
+	if len(file.Policies) != 1 || file.Vars["team"] != "web" || len(file.Groups) != 1 {
+		t.Fatalf("file-level blocks not kept:\n%s", merged)
+	}
+	if g := file.Groups[0].Labels[0]; g.Color != "00aadd" || g.Description != "Go code" || len(file.Labels) != 2 {
+		t.Errorf("grouped label not updated in its group:\n%s", merged)
+	}
+
+	merged, _, err = Merge([]byte("vars = { team = \"web\" }\n\n[[labels]]\nname = \"bug\"\ncolor = \"d73a4a\"\n\n[[policies]]\nmatch = \"bug\"\nprotected = true\n"), FormatTOML, []api.Label{{Name: "bug", Color: "ff0000"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"[[policies]]", "team = \"web\"", "color = \"ff0000\""} {
+		if !strings.Contains(string(merged), want) {
+			t.Errorf("merged TOML is missing %q:\n%s", want, merged)
+		}
+	}
+	if strings.Count(string(merged), "protected") != 1 {
+		t.Errorf("policy copied into the label:\n%s", merged)
+	}
+}
+
+func TestMergeKeepsTemplates(t *testing.T) {
+	data := `vars:
+  color: d73a4a
+labels:
+  - name: bug
+    color: ${color}
+    description: Something is broken
+  - name: "component: ${service}"
+    color: 0075ca
+    description: Code owned by ${team}
+`
+	merged, result, err := MergeYAML([]byte(data), []api.Label{
+		{Name: "bug", Color: "d73a4a", Description: "Broken"},
+		{Name: "component: billing", Color: "0075ca", Description: "Code owned by payments"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Updated != 1 || result.Added != 0 {
+		t.Errorf("result = %+v, want only the description of bug updated", result)
+	}
+
+	out := string(merged)
+	for _, want := range []string{"color: ${color}\n    description: Broken\n", `name: "component: ${service}"`, "Code owned by ${team}"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("merged file is missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "billing") {
+		t.Errorf("label covered by a templated name was appended:\n%s", out)
+	}
+
+	merged, _, err = Merge([]byte(`{"labels": [{"name": "bug", "color": "${color}"}, {"name": "team: ${team}", "color": "ffffff"}]}`), FormatJSON, []api.Label{
+		{Name: "bug", Color: "d73a4a"},
+		{Name: "team: web", Color: "ffffff"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(merged), `"${color}"`) || strings.Contains(string(merged), "team: web") {
+		t.Errorf("templates not kept when rewriting:\n%s", merged)
+	}
+}
+
+func TestMatchTemplate(t *testing.T) {
+	tests := []struct {
+		template, s string
+		want        bool
+	}{
+		{"component: ${service}", "component: billing", true},
+		{"component: ${service}", "area: billing", false},
+		{"${team}/${area}", "web/docs", true},
+		{"cost: $$${amount}", "cost: $5", true},
+		{"v1.${minor}", "v1x2", false},
+	}
+	for _, tt := range tests {
+		if got := MatchTemplate(tt.template, tt.s); got != tt.want {
+			t.Errorf("MatchTemplate(%q, %q) = %v, want %v", tt.template, tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestMergeMatchesConditions(t *testing.T) {
+	data := `labels:
+  - name: lang
+    color: 00add8
+    when:
+      language: [Go]
+groups:
+  - name: rust
+    when:
+      language: [Rust]
+    labels:
+      - name: lang
+        color: dea584
+`
+	merged, result, err := MergeYAML([]byte(data), []api.Label{
+		{Name: "lang", Color: "ff0000", When: &api.Condition{Language: []string{"Rust"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Updated != 1 || !strings.Contains(string(merged), "color: 00add8") || strings.Contains(string(merged), "dea584") {
+		t.Errorf("wrong definition updated (%+v):\n%s", result, merged)
+	}
+
+	if _, _, err := MergeYAML([]byte(data), []api.Label{{Name: "lang", Color: "ff0000"}}); err == nil {
+		t.Error("expected a label defined under several conditions to be refused")
+	}
+
+}
+
+func TestSniffGroupOnlyFile(t *testing.T) {
+	labelFile, err := parseSniffed("-", []byte("groups:\n  - labels:\n      - name: bug\n        color: d73a4a\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(labelFile.Groups) != 1 {
+		t.Errorf("groups = %+v, want the group kept", labelFile.Groups)
+	}
+}
@@ -0,0 +1,102 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// tomlLine matches a TOML table header or key/value pair
+var tomlLine = regexp.MustCompile(`^(\[\[?[\w.-]+\]\]?|[\w-]+\s*=)`)
+
+// knownExtension reports whether the file's format follows from its extension
+func knownExtension(filename string) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yml", ".yaml", ".json", ".csv", ".toml", ".md", ".markdown":
+		return filename != "-"
+	}
+	return false
+}
+
+// sniffFormats returns the formats the content could be in, most likely
+// first. There is always at least one.
+func sniffFormats(data []byte) []Format {
+	content := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if len(content) == 0 {
+		return []Format{FormatYAML}
+	}
+
+	line := firstLine(content)
+	switch {
+	case strings.HasPrefix(line, "[["):
+		// Only TOML starts with an array of tables
+		return []Format{FormatTOML, detectStructure(data, FormatYAML)}
+	case content[0] == '{' || content[0] == '[':
+		return []Format{detectStructure(data, FormatJSON), detectStructure(data, FormatYAML), FormatTOML}
+	case isCSVHeader(line):
+		return []Format{FormatCSV, detectStructure(data, FormatYAML)}
+	case tomlLine.MatchString(line):
+		return []Format{FormatTOML, detectStructure(data, FormatYAML)}
+	default:
+		return []Format{detectStructure(data, FormatYAML), FormatTOML, FormatCSV}
+	}
+}
+
+// firstLine returns the first line that is not blank or a comment
+func firstLine(content []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return line
+		}
+	}
+	return ""
+}
+
+// isCSVHeader reports whether a line is a CSV header naming the name and
+// color columns
+func isCSVHeader(line string) bool {
+	var name, color bool
+	for _, col := range strings.Split(line, ",") {
+		switch strings.ToLower(strings.Trim(strings.TrimSpace(col), `"`)) {
+		case "name":
+			name = true
+		case "color":
+			color = true
+		}
+	}
+	return name && color
+}
+
+// parseSniffed tries each format the content could be in, returning the
+// first that yields labels, or an error listing every attempt
+func parseSniffed(filename string, data []byte) (*LabelFile, error) {
+	var attempts []string
+	for _, format := range sniffFormats(data) {
+		labelFile, err := parseLabelFile(data, format)
+		if err == nil && labelFile.hasLabels() {
+			return labelFile, nil
+		}
+		if err == nil {
+			err = fmt.Errorf("no labels found")
+		}
+		attempts = append(attempts, fmt.Sprintf("  %s: %v", format, err))
+	}
+
+	name := filename
+	if name == "-" {
+		name = "stdin"
+	}
+	return nil, fmt.Errorf("could not determine the format of %s (use --input-format to choose one); tried:\n%s", name, strings.Join(attempts, "\n"))
+}
+
+// hasLabels reports whether a file defines any labels, at the top level or
+// in a group
+func (f *LabelFile) hasLabels() bool {
+	return len(f.Labels) > 0 || slices.ContainsFunc(f.Groups, func(g LabelGroup) bool { return len(g.Labels) > 0 })
+}
@@ -0,0 +1,256 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/scttfrdmn/gh-label-sync/pkg/api"
+)
+
+// Prefixes of template variables that are not defined by --var or vars
+const (
+	envPrefix  = "env."
+	repoPrefix = "repo."
+)
+
+// Vars are the values of ${name} references in a label file
+type Vars map[string]string
+
+// Template supplies the variables for expanding ${name} references in a label
+// file, and the repository that when conditions are checked against. Names
+// starting with env. are read from the environment, and repo.owner,
+// repo.name, and repo.topics (comma-separated) describe the target
+// repository.
+type Template struct {
+	Vars Vars
+
+	// Repo describes the target repository. It is only called if the file
+	// refers to a repo variable or has conditions.
+	Repo func() (*api.Repository, error)
+
+	repo *api.Repository
+}
+
+// ParseVars parses NAME=VALUE assignments, as given with --var
+func ParseVars(assignments []string) (Vars, error) {
+	vars := make(Vars)
+	for _, a := range assignments {
+		name, value, ok := strings.Cut(a, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid variable %q (use NAME=VALUE)", a)
+		}
+		vars[strings.TrimSpace(name)] = value
+	}
+	return vars, nil
+}
+
+// Merge returns the variables of v with those of over layered on top
+func (v Vars) Merge(over Vars) Vars {
+	merged := make(Vars, len(v)+len(over))
+	for name, value := range v {
+		merged[name] = value
+	}
+	for name, value := range over {
+		merged[name] = value
+	}
+	return merged
+}
+
+// Expand replaces ${name} references in s. $$ is a literal dollar sign, and a
+// $ not followed by { or $ is left alone.
+func (t *Template) Expand(s string) (string, error) {
+	if !strings.Contains(s, "$") {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		switch s[i+1] {
+		case '$':
+			b.WriteByte('$')
+			i++
+		case '{':
+			end := strings.IndexByte(s[i+2:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated variable in %q", s)
+			}
+			name := strings.TrimSpace(s[i+2 : i+2+end])
+			value, err := t.lookup(name)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(value)
+			i += end + 2
+		default:
+			b.WriteByte('$')
+		}
+	}
+	return b.String(), nil
+}
+
+func (t *Template) lookup(name string) (string, error) {
+	if env, ok := strings.CutPrefix(name, envPrefix); ok {
+		if value, ok := os.LookupEnv(env); ok {
+			return value, nil
+		}
+		return "", fmt.Errorf("undefined variable %q (environment variable not set)", name)
+	}
+
+	if strings.HasPrefix(name, repoPrefix) {
+		repo, err := t.repository()
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", name, err)
+		}
+		switch strings.TrimPrefix(name, repoPrefix) {
+		case "owner":
+			return repo.Owner, nil
+		case "name":
+			return repo.Name, nil
+		case "topics":
+			return strings.Join(repo.Topics, ","), nil
+		}
+		return "", fmt.Errorf("undefined variable %q (use repo.owner, repo.name, or repo.topics)", name)
+	}
+
+	if value, ok := t.Vars[name]; ok {
+		return value, nil
+	}
+	if len(t.Vars) == 0 {
+		return "", fmt.Errorf("undefined variable %q (define it with --var or a vars block)", name)
+	}
+	names := make([]string, 0, len(t.Vars))
+	for n := range t.Vars {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return "", fmt.Errorf("undefined variable %q (defined: %s)", name, strings.Join(names, ", "))
+}
+
+// repository returns the target repository, describing it on first use
+func (t *Template) repository() (*api.Repository, error) {
+	if t.repo != nil {
+		return t.repo, nil
+	}
+	if t.Repo == nil {
+		return nil, fmt.Errorf("no repository to describe")
+	}
+	repo, err := t.Repo()
+	if err != nil {
+		return nil, err
+	}
+	t.repo = repo
+	return repo, nil
+}
+
+// expand evaluates the templates in the label file. The file's vars block is
+// expanded first, without its own variables, and the template's variables
+// take precedence over it.
+func (f *LabelFile) expand(base *Template) error {
+	fileVars := make(Vars, len(f.Vars))
+	for name, value := range f.Vars {
+		expanded, err := base.Expand(value)
+		if err != nil {
+			return fmt.Errorf("vars: %s: %w", name, err)
+		}
+		fileVars[name] = expanded
+	}
+	tmpl := Template{Vars: fileVars.Merge(base.Vars), Repo: base.repository}
+
+	for i := range f.Labels {
+		if err := expandLabel(&f.Labels[i], &tmpl); err != nil {
+			return fmt.Errorf("label %q: %w", f.Labels[i].Name, err)
+		}
+	}
+
+	for i := range f.Policies {
+		match, err := tmpl.Expand(f.Policies[i].Match)
+		if err != nil {
+			return fmt.Errorf("policies: %w", err)
+		}
+		f.Policies[i].Match = match
+	}
+
+	return nil
+}
+
+func expandLabel(label *api.Label, tmpl *Template) error {
+	for _, field := range []*string{&label.Name, &label.Color, &label.Description} {
+		expanded, err := tmpl.Expand(*field)
+		if err != nil {
+			return err
+		}
+		*field = expanded
+	}
+
+	for i, alias := range label.Aliases {
+		expanded, err := tmpl.Expand(alias)
+		if err != nil {
+			return err
+		}
+		label.Aliases[i] = expanded
+	}
+
+	return nil
+}
+
+// filter moves labels whose conditions the repository does not meet to
+// Excluded. The repository is only described if some label has a condition.
+func (f *LabelFile) filter(tmpl *Template) error {
+	kept := f.Labels[:0]
+	for _, label := range f.Labels {
+		if label.When != nil {
+			repo, err := tmpl.repository()
+			if err != nil {
+				return fmt.Errorf("label %q: when: %w", label.Name, err)
+			}
+			if !label.When.Matches(repo) {
+				f.Excluded = append(f.Excluded, label)
+				continue
+			}
+		}
+		kept = append(kept, label)
+	}
+	f.Labels = kept
+	return nil
+}
+
+// IsTemplate reports whether s contains a ${name} reference
+func IsTemplate(s string) bool {
+	return strings.Contains(s, "${")
+}
+
+// MatchTemplate reports whether s could be an expansion of template, with
+// each ${name} reference standing for any text
+func MatchTemplate(template, s string) bool {
+	var b strings.Builder
+	b.WriteString(`^(?s:`)
+	for i := 0; i < len(template); i++ {
+		if template[i] == '$' && i+1 < len(template) {
+			switch template[i+1] {
+			case '$':
+				b.WriteString(`\$`)
+				i++
+				continue
+			case '{':
+				if end := strings.IndexByte(template[i+2:], '}'); end >= 0 {
+					b.WriteString(`.*`)
+					i += end + 2
+					continue
+				}
+			}
+		}
+		b.WriteString(regexp.QuoteMeta(template[i : i+1]))
+	}
+	b.WriteString(`)$`)
+
+	re, err := regexp.Compile(b.String())
+	return err == nil && re.MatchString(s)
+}
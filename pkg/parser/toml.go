@@ -0,0 +1,31 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/BurntSushi/toml"
+	"github.com/scttfrdmn/gh-label-sync/pkg/api"
+)
+
+func parseTOML(r io.Reader) (*LabelFile, error) {
+	var labelFile LabelFile
+	if _, err := toml.NewDecoder(r).Decode(&labelFile); err != nil {
+		return nil, fmt.Errorf("failed to parse TOML: %w", err)
+	}
+	return &labelFile, nil
+}
+
+// WriteTOML writes labels to TOML format, as a [[labels]] array of tables
+func WriteTOML(w io.Writer, labels []api.Label) error {
+	return writeTOMLFile(w, &LabelFile{Labels: labels})
+}
+
+// writeTOMLFile writes a label file to TOML format, with its policies, vars,
+// and groups
+func writeTOMLFile(w io.Writer, labelFile *LabelFile) error {
+	if err := toml.NewEncoder(w).Encode(labelFile); err != nil {
+		return fmt.Errorf("failed to write TOML: %w", err)
+	}
+	return nil
+}
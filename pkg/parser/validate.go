@@ -0,0 +1,314 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/scttfrdmn/gh-label-sync/pkg/api"
+	"github.com/scttfrdmn/gh-label-sync/pkg/color"
+	"gopkg.in/yaml.v3"
+)
+
+// Limits GitHub enforces on label fields
+const (
+	maxNameLength        = 50
+	maxDescriptionLength = 100
+)
+
+// Position is a 1-based line and column in a label file. A zero line means
+// the position is unknown.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// Problem describes an invalid label definition
+type Problem struct {
+	File string
+	Position
+	Index   int // index of the label in the file
+	Label   string
+	Message string
+}
+
+func (p Problem) Error() string {
+	loc := p.File
+	if p.Line > 0 {
+		loc = fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+	}
+	if p.Label != "" {
+		return fmt.Sprintf("%s: %s: %s", loc, p.Label, p.Message)
+	}
+	return fmt.Sprintf("%s: %s", loc, p.Message)
+}
+
+// ValidateFile parses a label file and checks each definition against the
+// limits GitHub enforces. Problems carry the position of the offending label
+// where the format allows it.
+func ValidateFile(filename string) ([]Problem, error) {
+	labels, err := ParseFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var positions []Position
+	if filename != "-" {
+		positions, err = LabelPositions(filename)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	problems := Validate(labels)
+	for i := range problems {
+		problems[i].File = filename
+		if idx := problems[i].Index; idx < len(positions) {
+			problems[i].Position = positions[idx]
+		}
+	}
+
+	return problems, nil
+}
+
+// Validate checks label definitions against the limits GitHub enforces.
+// Problems are returned without file positions; use ValidateFile for those.
+func Validate(labels []api.Label) []Problem {
+	var problems []Problem
+	seen := make(map[string][]api.Label)
+
+	report := func(i int, label api.Label, format string, args ...any) {
+		problems = append(problems, Problem{
+			Index:   i,
+			Label:   label.Name,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	for i, label := range labels {
+		name := strings.TrimSpace(label.Name)
+		if name == "" {
+			report(i, label, "name is required")
+		} else if len([]rune(label.Name)) > maxNameLength {
+			report(i, label, "name is longer than %d characters", maxNameLength)
+		}
+
+		// Labels marked for deletion only need a name, and templated colors
+		// are only known at sync time
+		if !label.Delete && !IsTemplate(label.Color) {
+			if label.Color == "" {
+				report(i, label, "color is required")
+			} else if _, err := color.Parse(label.Color); err != nil || len(label.Color) != 6 {
+				report(i, label, "color %q must be 6 hex digits", label.Color)
+			}
+		}
+
+		if len([]rune(label.Description)) > maxDescriptionLength {
+			report(i, label, "description is longer than %d characters", maxDescriptionLength)
+		}
+
+		if !label.Policy.Valid() {
+			report(i, label, "policy %q must be create-only, enforce, or ignore", label.Policy)
+		}
+		if label.Protected && label.Delete {
+			report(i, label, "protected label cannot be marked for deletion")
+		}
+
+		// GitHub treats label names case-insensitively. The same label may be
+		// defined for repositories that cannot match both conditions, such
+		// as Go and Python repositories.
+		key := strings.ToLower(name)
+		if name != "" && slices.ContainsFunc(seen[key], func(other api.Label) bool { return !other.When.Excludes(label.When) }) {
+			report(i, label, "duplicate label name")
+		}
+		seen[key] = append(seen[key], label)
+	}
+
+	return problems
+}
+
+// LabelPositions returns the position of each label definition in the file,
+// in the order ParseFile returns them
+func LabelPositions(filename string) ([]Position, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	switch DetectFormat(filename, data) {
+	case FormatCSV:
+		return csvPositions(data)
+	case FormatTOML, FormatMarkdown:
+		// Positions are not tracked for these formats
+		return nil, nil
+	}
+
+	// JSON is also YAML, but its positions come from the token stream
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("{")) || bytes.HasPrefix(trimmed, []byte("[")) {
+		if positions, err := jsonPositions(data); err == nil {
+			return positions, nil
+		}
+	}
+	return yamlPositions(data)
+}
+
+func yamlPositions(data []byte) ([]Position, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	// Labels are either under a top-level labels key or, in
+	// github-label-sync and labeler files, the document itself. Grouped
+	// labels follow, as ParseFile adds them after the others.
+	root := doc.Content[0]
+	seqs := []*yaml.Node{root}
+	if root.Kind == yaml.MappingNode {
+		seqs = []*yaml.Node{mappingValue(root, "labels")}
+		if groups := mappingValue(root, "groups"); groups != nil && groups.Kind == yaml.SequenceNode {
+			for _, group := range groups.Content {
+				seqs = append(seqs, mappingValue(group, "labels"))
+			}
+		}
+	}
+
+	var positions []Position
+	for _, seq := range seqs {
+		if seq == nil || seq.Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, item := range seq.Content {
+			positions = append(positions, Position{Line: item.Line, Column: item.Column})
+		}
+	}
+
+	return positions, nil
+}
+
+// jsonFrame is an object or array being read by jsonPositions
+type jsonFrame struct {
+	object bool
+
+	// key is the key of the object's current value, and wantKey is set
+	// while the next token is a key
+	key     string
+	wantKey bool
+}
+
+func jsonPositions(data []byte) ([]Position, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	// Walk tokens, recording the offset of each object in the top-level
+	// "labels" array or a top-level array, and separately of each object in
+	// the "labels" array of a group
+	var offsets, grouped []int64
+	var stack []jsonFrame
+	valueDone := func() {
+		if n := len(stack); n > 0 && stack[n-1].object {
+			stack[n-1].wantKey = true
+		}
+	}
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case json.Delim:
+			if t == '}' || t == ']' {
+				stack = stack[:len(stack)-1]
+				valueDone()
+				continue
+			}
+			if t == '{' {
+				switch jsonPath(stack) {
+				case "[]", "labels/[]":
+					offsets = append(offsets, decoder.InputOffset()-1)
+				case "groups/[]/labels/[]":
+					grouped = append(grouped, decoder.InputOffset()-1)
+				}
+			}
+			stack = append(stack, jsonFrame{object: t == '{', wantKey: true})
+		case string:
+			if n := len(stack); n > 0 && stack[n-1].object && stack[n-1].wantKey {
+				stack[n-1].key = t
+				stack[n-1].wantKey = false
+				continue
+			}
+			valueDone()
+		default:
+			valueDone()
+		}
+	}
+
+	var positions []Position
+	for _, off := range append(offsets, grouped...) {
+		positions = append(positions, offsetPosition(data, off))
+	}
+
+	return positions, nil
+}
+
+// jsonPath describes where the next value is, such as "groups/[]/labels/[]"
+// for a label in a group
+func jsonPath(stack []jsonFrame) string {
+	parts := make([]string, len(stack))
+	for i, f := range stack {
+		parts[i] = "[]"
+		if f.object {
+			parts[i] = f.key
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func csvPositions(data []byte) ([]Position, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+
+	// Skip header
+	if _, err := reader.Read(); err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	var positions []Position
+	for {
+		_, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV row: %w", err)
+		}
+		line, col := reader.FieldPos(0)
+		positions = append(positions, Position{Line: line, Column: col})
+	}
+
+	return positions, nil
+}
+
+// offsetPosition converts a byte offset into a line and column
+func offsetPosition(data []byte, offset int64) Position {
+	line, col := 1, 1
+	for _, b := range data[:offset] {
+		if b == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+	return Position{Line: line, Column: col}
+}
@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/scttfrdmn/gh-label-sync/pkg/api"
+)
+
+func TestValidateDuplicatesRespectConditions(t *testing.T) {
+	goRepos := &api.Condition{Language: []string{"Go"}}
+	pyRepos := &api.Condition{Language: []string{"Python"}}
+	public := &api.Condition{Visibility: []string{"public"}}
+
+	labels := []api.Label{
+		{Name: "lint", Color: "00add8", When: goRepos},
+		{Name: "lint", Color: "3572a5", When: pyRepos},
+		{Name: "docs", Color: "0075ca", When: goRepos},
+		{Name: "Docs", Color: "0075ca", When: public},
+	}
+
+	problems := Validate(labels)
+	if len(problems) != 1 || problems[0].Index != 3 || problems[0].Message != "duplicate label name" {
+		t.Errorf("problems = %v, want only Docs reported as a duplicate", problems)
+	}
+}
+
+func TestPositionsIncludeGroups(t *testing.T) {
+	yamlData := `groups:
+  - when:
+      language: [Go]
+    labels:
+      - name: lang
+        color: 00add8
+labels:
+  - name: bug
+    color: d73a4a
+`
+	jsonData := `{
+  "groups": [
+    {"when": {"language": ["Go"]}, "labels": [
+      {"name": "lang", "color": "00add8"}
+    ]}
+  ],
+  "labels": [
+    {"name": "bug", "color": "d73a4a", "aliases": ["defect"]}
+  ]
+}`
+	tests := []struct {
+		name      string
+		positions func([]byte) ([]Position, error)
+		data      string
+		want      []Position
+	}{
+		{"yaml", yamlPositions, yamlData, []Position{{Line: 8, Column: 5}, {Line: 5, Column: 9}}},
+		{"json", jsonPositions, jsonData, []Position{{Line: 8, Column: 5}, {Line: 4, Column: 7}}},
+	}
+	for _, tt := range tests {
+		got, err := tt.positions([]byte(tt.data))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if len(got) != len(tt.want) || got[0] != tt.want[0] || got[1] != tt.want[1] {
+			t.Errorf("%s: positions = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
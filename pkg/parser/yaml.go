@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"bytes"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
@@ -10,75 +11,249 @@ import (
 	"strings"
 
 	"github.com/scttfrdmn/gh-label-sync/pkg/api"
+	"github.com/scttfrdmn/gh-label-sync/pkg/markdown"
 	"gopkg.in/yaml.v3"
 )
 
 type LabelFile struct {
-	Labels []api.Label `json:"labels" yaml:"labels"`
+	Labels []api.Label `json:"labels" yaml:"labels" toml:"labels"`
+
+	// Policies set the policy or protection of labels by name pattern, in
+	// the file and in the repository
+	Policies []api.PolicyRule `json:"policies,omitempty" yaml:"policies,omitempty" toml:"policies,omitempty"`
+
+	// Vars define ${name} template variables, which --var overrides
+	Vars map[string]string `json:"vars,omitempty" yaml:"vars,omitempty" toml:"vars,omitempty"`
+
+	// Groups are labels that share a condition
+	Groups []LabelGroup `json:"groups,omitempty" yaml:"groups,omitempty" toml:"groups,omitempty"`
+
+	// Excluded are the labels whose conditions the repository does not meet
+	Excluded []api.Label `json:"-" yaml:"-" toml:"-"`
+}
+
+// LabelGroup is a set of labels limited to repositories matching a condition
+type LabelGroup struct {
+	Name   string         `json:"name,omitempty" yaml:"name,omitempty" toml:"name,omitempty"`
+	When   *api.Condition `json:"when,omitempty" yaml:"when,omitempty" toml:"when,omitempty"`
+	Labels []api.Label    `json:"labels" yaml:"labels" toml:"labels"`
 }
 
-// ParseFile parses a label file (YAML, JSON, or CSV) based on file extension
+// ParseFile parses a label file. The format comes from the file extension
+// or, for stdin and unknown extensions, from the content; YAML and JSON are
+// further told apart from other tools' formats by the document's structure.
 func ParseFile(filename string) ([]api.Label, error) {
-	var labels []api.Label
+	return ParseFileFormat(filename, "")
+}
+
+// ParseFileFormat parses a label file in the given format. An empty format
+// is detected as in ParseFile. Templates are left as written.
+func ParseFileFormat(filename string, format Format) ([]api.Label, error) {
+	labelFile, err := ParseLabelFile(filename, format, nil)
+	if err != nil {
+		return nil, err
+	}
+	return labelFile.Labels, nil
+}
+
+// ParseLabelFile parses a label file like ParseFileFormat, returning its
+// policy rules along with the labels. With a template, ${name} references
+// are expanded first. The rules have already been applied to the file's
+// labels.
+func ParseLabelFile(filename string, format Format, tmpl *Template) (*LabelFile, error) {
+	data, err := ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return ParseLabelData(filename, data, format, tmpl)
+}
+
+// ReadFile reads a label file, or stdin for "-"
+func ReadFile(filename string) ([]byte, error) {
+	var data []byte
 	var err error
 
 	// Handle stdin
 	if filename == "-" {
-		return parseYAML(os.Stdin)
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(filename)
 	}
-
-	// Open file
-	file, err := os.Open(filename)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
-	defer file.Close()
+	return data, nil
+}
+
+// ParseLabelData parses the contents of a label file like ParseLabelFile.
+// The filename is only used to detect the format, so a file read once can be
+// parsed again with a different template.
+func ParseLabelData(filename string, data []byte, format Format, tmpl *Template) (*LabelFile, error) {
+	labelFile, err := decodeLabelData(filename, data, format)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := labelFile.prepare(tmpl); err != nil {
+		return nil, err
+	}
+
+	// Normalize colors
+	for i := range labelFile.Labels {
+		if !IsTemplate(labelFile.Labels[i].Color) {
+			labelFile.Labels[i].Color = api.NormalizeColor(labelFile.Labels[i].Color)
+		}
+	}
+
+	return labelFile, nil
+}
+
+// decodeLabelData parses the contents of a label file as written: groups are
+// kept, templates are not expanded, and policy rules are not applied. An
+// empty format is detected as in ParseFile.
+func decodeLabelData(filename string, data []byte, format Format) (*LabelFile, error) {
+	switch {
+	case format != "":
+		return parseLabelFile(data, format)
+	case knownExtension(filename):
+		return parseLabelFile(data, DetectFormat(filename, data))
+	default:
+		return parseSniffed(filename, data)
+	}
+}
 
-	// Determine format by extension
-	ext := strings.ToLower(filepath.Ext(filename))
-	switch ext {
+// DetectFormat guesses a file's format from its extension or, for stdin and
+// unknown extensions, its content. YAML and JSON are refined by structure.
+func DetectFormat(filename string, data []byte) Format {
+	switch strings.ToLower(filepath.Ext(filename)) {
 	case ".yml", ".yaml":
-		labels, err = parseYAML(file)
+		return detectStructure(data, FormatYAML)
 	case ".json":
-		labels, err = parseJSON(file)
+		return detectStructure(data, FormatJSON)
 	case ".csv":
-		labels, err = parseCSV(file)
+		return FormatCSV
+	case ".toml":
+		return FormatTOML
+	case ".md", ".markdown":
+		return FormatMarkdown
 	default:
-		// Try YAML as default
-		labels, err = parseYAML(file)
-		if err != nil {
-			return nil, fmt.Errorf("unsupported file format (use .yml, .json, or .csv): %w", err)
-		}
+		return sniffFormats(data)[0]
 	}
+}
 
+// Parse parses label definitions in the given format. Markdown is
+// write-only.
+func Parse(data []byte, format Format) ([]api.Label, error) {
+	labelFile, err := parseLabelFile(data, format)
 	if err != nil {
 		return nil, err
 	}
+	if err := labelFile.prepare(nil); err != nil {
+		return nil, err
+	}
+	return labelFile.Labels, nil
+}
 
-	// Normalize colors
-	for i := range labels {
-		labels[i].Color = api.NormalizeColor(labels[i].Color)
+// parseLabelFile parses a label file in the given format
+func parseLabelFile(data []byte, format Format) (*LabelFile, error) {
+	r := bytes.NewReader(data)
+
+	var labelFile *LabelFile
+	var labels []api.Label
+	var err error
+	switch format {
+	case FormatYAML:
+		labelFile, err = parseYAML(r)
+	case FormatJSON:
+		labelFile, err = parseJSON(r)
+	case FormatTOML:
+		labelFile, err = parseTOML(r)
+	case FormatCSV:
+		labels, err = parseCSV(r)
+	case FormatLabelSync:
+		labels, err = parseLabelSync(r)
+	case FormatLabeler:
+		labels, err = parseLabeler(r)
+	case FormatProbot:
+		labels, err = parseProbot(r)
+	case FormatMarkdown:
+		return nil, fmt.Errorf("cannot read %s files", format)
+	default:
+		return nil, fmt.Errorf("unsupported format: %s (use %s)", format, formatList())
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return labels, nil
+	// Other tools' formats have no policies
+	if labelFile == nil {
+		return &LabelFile{Labels: labels}, nil
+	}
+	return labelFile, nil
+}
+
+// prepare moves grouped labels into the label list and, when tmpl is
+// non-nil, expands templates and drops labels whose conditions the
+// repository does not meet. It then validates the policies and applies the
+// policy rules.
+func (f *LabelFile) prepare(tmpl *Template) error {
+	for _, group := range f.Groups {
+		for _, label := range group.Labels {
+			label.When = label.When.And(group.When)
+			f.Labels = append(f.Labels, label)
+		}
+	}
+	f.Groups = nil
+
+	for _, label := range f.Labels {
+		if label.When == nil {
+			continue
+		}
+		if err := label.When.Validate(); err != nil {
+			return fmt.Errorf("label %q: when: %w", label.Name, err)
+		}
+	}
+
+	if tmpl != nil {
+		if err := f.expand(tmpl); err != nil {
+			return err
+		}
+		if err := f.filter(tmpl); err != nil {
+			return err
+		}
+	}
+
+	for _, rule := range f.Policies {
+		if err := rule.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, label := range f.Labels {
+		if !label.Policy.Valid() {
+			return fmt.Errorf("invalid policy %q for label %q (use create-only, enforce, or ignore)", label.Policy, label.Name)
+		}
+	}
+	api.ApplyPolicies(f.Labels, f.Policies)
+
+	return nil
 }
 
-func parseYAML(r io.Reader) ([]api.Label, error) {
+func parseYAML(r io.Reader) (*LabelFile, error) {
 	var labelFile LabelFile
 	decoder := yaml.NewDecoder(r)
 	if err := decoder.Decode(&labelFile); err != nil {
 		return nil, fmt.Errorf("failed to parse YAML: %w", err)
 	}
-	return labelFile.Labels, nil
+	return &labelFile, nil
 }
 
-func parseJSON(r io.Reader) ([]api.Label, error) {
+func parseJSON(r io.Reader) (*LabelFile, error) {
 	var labelFile LabelFile
 	decoder := json.NewDecoder(r)
 	if err := decoder.Decode(&labelFile); err != nil {
 		return nil, fmt.Errorf("failed to parse JSON: %w", err)
 	}
-	return labelFile.Labels, nil
+	return &labelFile, nil
 }
 
 func parseCSV(r io.Reader) ([]api.Label, error) {
@@ -134,7 +309,12 @@ func parseCSV(r io.Reader) ([]api.Label, error) {
 
 // WriteYAML writes labels to YAML format
 func WriteYAML(w io.Writer, labels []api.Label) error {
-	labelFile := LabelFile{Labels: labels}
+	return writeYAMLFile(w, &LabelFile{Labels: labels})
+}
+
+// writeYAMLFile writes a label file to YAML format, with its policies, vars,
+// and groups
+func writeYAMLFile(w io.Writer, labelFile *LabelFile) error {
 	encoder := yaml.NewEncoder(w)
 	encoder.SetIndent(2)
 	if err := encoder.Encode(labelFile); err != nil {
@@ -145,7 +325,12 @@ func WriteYAML(w io.Writer, labels []api.Label) error {
 
 // WriteJSON writes labels to JSON format
 func WriteJSON(w io.Writer, labels []api.Label) error {
-	labelFile := LabelFile{Labels: labels}
+	return writeJSONFile(w, &LabelFile{Labels: labels})
+}
+
+// writeJSONFile writes a label file to JSON format, with its policies, vars,
+// and groups
+func writeJSONFile(w io.Writer, labelFile *LabelFile) error {
 	encoder := json.NewEncoder(w)
 	encoder.SetIndent("", "  ")
 	if err := encoder.Encode(labelFile); err != nil {
@@ -153,3 +338,47 @@ func WriteJSON(w io.Writer, labels []api.Label) error {
 	}
 	return nil
 }
+
+// writeLabelFile writes a whole label file in one of the tool's own formats
+func writeLabelFile(w io.Writer, labelFile *LabelFile, format Format) error {
+	switch format {
+	case FormatYAML:
+		return writeYAMLFile(w, labelFile)
+	case FormatJSON:
+		return writeJSONFile(w, labelFile)
+	case FormatTOML:
+		return writeTOMLFile(w, labelFile)
+	}
+	return fmt.Errorf("%s files have no policies, vars, or groups", format)
+}
+
+// WriteCSV writes labels to CSV format with name, color, and description
+// columns
+func WriteCSV(w io.Writer, labels []api.Label) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"name", "color", "description"}); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	for _, label := range labels {
+		if label.Delete {
+			continue
+		}
+		if err := writer.Write([]string{label.Name, label.Color, label.Description}); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+// WriteMarkdown writes labels as a Markdown table with a badge for each
+// label, for use in a README
+func WriteMarkdown(w io.Writer, labels []api.Label) error {
+	if _, err := io.WriteString(w, markdown.LabelTable(labels)); err != nil {
+		return fmt.Errorf("failed to write Markdown: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/scttfrdmn/gh-label-sync/pkg/diff"
+	"github.com/scttfrdmn/gh-label-sync/pkg/format"
+	"golang.org/x/term"
+)
+
+// ErrCancelled is returned when the user aborts an interactive selection
+var ErrCancelled = errors.New("cancelled")
+
+// Key codes read from the terminal in raw mode
+const (
+	keyCtrlC  = 3
+	keyEnter  = '\r'
+	keyEscape = 27
+)
+
+// selectCheckbox renders a checkbox list and lets the user toggle items with
+// the keyboard. The terminal is put in raw mode for the duration.
+func selectCheckbox(items []diff.LabelDiff, checked []bool, style format.Style) ([]bool, error) {
+	fd := int(os.Stdin.Fd())
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enable raw terminal mode: %w", err)
+	}
+	defer term.Restore(fd, oldState)
+
+	cursor := 0
+	lines := render(items, checked, cursor, 0, style)
+
+	buf := make([]byte, 3)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case n == 3 && buf[0] == keyEscape && buf[1] == '[' && buf[2] == 'A':
+			cursor = (cursor - 1 + len(items)) % len(items)
+		case n == 3 && buf[0] == keyEscape && buf[1] == '[' && buf[2] == 'B':
+			cursor = (cursor + 1) % len(items)
+		case n == 1 && buf[0] == 'k':
+			cursor = (cursor - 1 + len(items)) % len(items)
+		case n == 1 && buf[0] == 'j':
+			cursor = (cursor + 1) % len(items)
+		case n == 1 && buf[0] == ' ':
+			checked[cursor] = !checked[cursor]
+		case n == 1 && buf[0] == 'a':
+			all := true
+			for _, c := range checked {
+				all = all && c
+			}
+			for i := range checked {
+				checked[i] = !all
+			}
+		case n == 1 && (buf[0] == keyEnter || buf[0] == '\n'):
+			fmt.Print("\r\n")
+			return checked, nil
+		case n == 1 && (buf[0] == keyCtrlC || buf[0] == keyEscape || buf[0] == 'q'):
+			fmt.Print("\r\n")
+			return nil, ErrCancelled
+		default:
+			continue
+		}
+
+		lines = render(items, checked, cursor, lines, style)
+	}
+}
+
+// render draws the checkbox list, first erasing the previously drawn lines,
+// and returns the number of lines written
+func render(items []diff.LabelDiff, checked []bool, cursor, previous int, style format.Style) int {
+	var sb strings.Builder
+
+	if previous > 0 {
+		sb.WriteString(fmt.Sprintf("\x1b[%dA\r\x1b[J", previous))
+	}
+
+	sb.WriteString("? Select changes to apply (↑/↓ move, space toggle, a all, enter confirm, q cancel)\r\n")
+	for i, item := range items {
+		pointer := " "
+		if i == cursor {
+			pointer = ">"
+		}
+		box := "[ ]"
+		if checked[i] {
+			box = "[x]"
+		}
+		sb.WriteString(fmt.Sprintf("%s %s %s\r\n", pointer, box, format.FormatDiffLine(item, style)))
+	}
+
+	fmt.Print(sb.String())
+	return len(items) + 1
+}
@@ -0,0 +1,112 @@
+package prompt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/scttfrdmn/gh-label-sync/pkg/diff"
+	"github.com/scttfrdmn/gh-label-sync/pkg/format"
+	"golang.org/x/term"
+)
+
+// stdin is shared by every prompt, so answers piped in ahead of time are not
+// lost in the buffer of an earlier prompt
+var stdin = bufio.NewReader(os.Stdin)
+
+// Confirm asks a yes/no question on stdin, defaulting to no
+func Confirm(question string) (bool, error) {
+	fmt.Printf("%s (y/N) ", question)
+	return readYesNo(stdin, false)
+}
+
+// SelectDiffs lets the user pick which changes to apply. The preselected diffs
+// start out checked. On a full terminal a checkbox list is shown; otherwise
+// each change is confirmed with a y/n prompt.
+func SelectDiffs(diffs, preselected []diff.LabelDiff) ([]diff.LabelDiff, error) {
+	var items []diff.LabelDiff
+	for _, d := range diffs {
+		if d.Type != diff.DiffTypeMatch && !diff.Blocked(d) && !d.Excluded {
+			items = append(items, d)
+		}
+	}
+
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	checked := make([]bool, len(items))
+	for i, item := range items {
+		for _, p := range preselected {
+			if p.Type == item.Type && p.Name == item.Name {
+				checked[i] = true
+				break
+			}
+		}
+	}
+
+	style := format.DetectStyle()
+
+	var err error
+	if isInteractiveTerminal() {
+		checked, err = selectCheckbox(items, checked, style)
+	} else {
+		checked, err = selectSequential(stdin, items, checked, style)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []diff.LabelDiff
+	for i, item := range items {
+		if checked[i] {
+			selected = append(selected, item)
+		}
+	}
+
+	return selected, nil
+}
+
+// selectSequential asks about each change in turn
+func selectSequential(reader *bufio.Reader, items []diff.LabelDiff, checked []bool, style format.Style) ([]bool, error) {
+	fmt.Println("\nSelect changes to apply:")
+	for i, item := range items {
+		hint := "y/N"
+		if checked[i] {
+			hint = "Y/n"
+		}
+		fmt.Printf("? %s (%s) ", format.FormatDiffLine(item, style), hint)
+
+		answer, err := readYesNo(reader, checked[i])
+		if err != nil {
+			return nil, err
+		}
+		checked[i] = answer
+	}
+
+	return checked, nil
+}
+
+// readYesNo reads a single y/n answer, returning def on an empty response
+func readYesNo(reader *bufio.Reader, def bool) (bool, error) {
+	response, err := reader.ReadString('\n')
+	if err != nil && (err != io.EOF || response == "") {
+		return false, err
+	}
+
+	switch strings.TrimSpace(strings.ToLower(response)) {
+	case "y", "yes":
+		return true, nil
+	case "n", "no":
+		return false, nil
+	default:
+		return def, nil
+	}
+}
+
+// isInteractiveTerminal reports whether both stdin and stdout are terminals
+func isInteractiveTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}